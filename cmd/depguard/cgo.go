@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	cgoFormats = map[string]cgoWriter{
+		"text": &textCgoWriter{},
+		"json": &jsonCgoWriter{},
+	}
+)
+
+// cgoFile is a file that introduces cgo by importing "C".
+type cgoFile struct {
+	Package string `json:"package"`
+	// File is relative to the working directory when it is within it.
+	File string `json:"file"`
+	// DeniedBy are the lists that did not allow the file to import "C".
+	DeniedBy []string `json:"deniedBy"`
+}
+
+// buildCgoFiles collects the files of the packages that introduce cgo, along with the
+// lists that denied it.
+func buildCgoFiles(results []*packageResult, wd string) []*cgoFile {
+	byFile := make(map[string]*cgoFile)
+	var files []*cgoFile
+	for _, pr := range userPackages(results) {
+		if pr.result == nil {
+			continue
+		}
+		for _, f := range pr.result.CgoFiles {
+			cf := &cgoFile{Package: pr.pkg.PkgPath, File: relativeFile(wd, f), DeniedBy: []string{}}
+			byFile[f] = cf
+			files = append(files, cf)
+		}
+		for _, v := range pr.result.Violations {
+			cf := byFile[v.File]
+			if v.Import != "C" || cf == nil || containsString(cf.DeniedBy, v.List) {
+				continue
+			}
+			cf.DeniedBy = append(cf.DeniedBy, v.List)
+		}
+	}
+	for _, cf := range files {
+		sort.Strings(cf.DeniedBy)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].File < files[j].File
+	})
+	return files
+}
+
+func containsString(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+type cgoWriter interface {
+	write(io.Writer, []*cgoFile) error
+}
+
+type textCgoWriter struct{}
+
+func (*textCgoWriter) write(w io.Writer, files []*cgoFile) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tPACKAGE\tDENIED BY")
+	for _, cf := range files {
+		deniedBy := "-"
+		if len(cf.DeniedBy) > 0 {
+			deniedBy = strings.Join(cf.DeniedBy, ", ")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", cf.File, cf.Package, deniedBy)
+	}
+	return tw.Flush()
+}
+
+type jsonCgoWriter struct{}
+
+func (*jsonCgoWriter) write(w io.Writer, files []*cgoFile) error {
+	if files == nil {
+		files = []*cgoFile{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(files)
+}
+
+// cgoMain runs the cgo command with its arguments, returning the exit code.
+func cgoMain(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("depguard cgo", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output `format` of the listing: text or json")
+	tests := fs.Bool("test", true, "include test packages")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: depguard cgo [-format text|json] [-test=false] [packages]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	cw, ok := cgoFormats[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown cgo format %q\n", *format)
+		return 2
+	}
+	analyzer, err := loadAnalyzer(stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	results, err := analyze(analyzer, *tests, patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	wd, _ := os.Getwd()
+	if err := cw.write(stdout, buildCgoFiles(results, wd)); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildCgoFiles(t *testing.T) {
+	wd, err := filepath.Abs("repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []*cgoFile{
+		{Package: "example.com/app", File: "app/a.go", DeniedBy: []string{"NoCgo"}},
+		{Package: "example.com/lib", File: "lib/l.go", DeniedBy: []string{}},
+	}
+	act := buildCgoFiles(testResults(wd), wd)
+	if diff := cmp.Diff(expected, act); diff != "" {
+		t.Errorf("Cgo files did not match expected\n%s", diff)
+	}
+}
+
+func TestCgoWriters(t *testing.T) {
+	files := []*cgoFile{
+		{Package: "example.com/app", File: "app/a.go", DeniedBy: []string{"Main", "NoCgo"}},
+		{Package: "example.com/internal/native", File: "internal/native/n.go", DeniedBy: []string{}},
+	}
+	scenarios := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "text",
+			expected: `FILE                  PACKAGE                      DENIED BY
+app/a.go              example.com/app              Main, NoCgo
+internal/native/n.go  example.com/internal/native  -
+`,
+		},
+		{
+			format: "json",
+			expected: `[
+  {
+    "package": "example.com/app",
+    "file": "app/a.go",
+    "deniedBy": [
+      "Main",
+      "NoCgo"
+    ]
+  },
+  {
+    "package": "example.com/internal/native",
+    "file": "internal/native/n.go",
+    "deniedBy": []
+  }
+]
+`,
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := cgoFormats[s.format].write(&buf, files); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(s.expected, buf.String()); diff != "" {
+				t.Errorf("Output did not match expected\n%s", diff)
+			}
+		})
+	}
+}
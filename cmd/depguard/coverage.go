@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	depguard "github.com/OpenPeeDeeP/depguard/v2"
+)
+
+var (
+	coverageFormats = map[string]coverageWriter{
+		"text": &textCoverageWriter{},
+		"json": &jsonCoverageWriter{},
+	}
+)
+
+// entryCoverage is how many imports an allow or deny entry of a list matched. The
+// other rules of a list, like denySymbols or visibility, are not counted.
+type entryCoverage struct {
+	List    string `json:"list"`
+	Kind    string `json:"kind"`
+	Entry   string `json:"entry"`
+	Matches int    `json:"matches"`
+}
+
+// configEntries are the allow and deny entries of every list as they are configured,
+// which needs to be read before the settings are compiled as that expands them.
+func configEntries(settings *depguard.LinterSettings) []*entryCoverage {
+	names := make([]string, 0, len(*settings))
+	for name, l := range *settings {
+		if l != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var entries []*entryCoverage
+	for _, name := range names {
+		l := (*settings)[name]
+		for _, a := range l.Allow {
+			entries = append(entries, &entryCoverage{List: name, Kind: "allow", Entry: a})
+		}
+		deny := make([]string, 0, len(l.Deny))
+		for d := range l.Deny {
+			deny = append(deny, d)
+		}
+		sort.Strings(deny)
+		for _, d := range deny {
+			entries = append(entries, &entryCoverage{List: name, Kind: "deny", Entry: d})
+		}
+	}
+	return entries
+}
+
+// countMatches counts the imports every entry matched. Entries the results match that
+// are not among the configured ones, like those of the default configuration, are
+// added after them.
+func countMatches(entries []*entryCoverage, results []*packageResult) []*entryCoverage {
+	type key struct{ list, kind, entry string }
+	byKey := make(map[key]*entryCoverage, len(entries))
+	for _, e := range entries {
+		byKey[key{e.List, e.Kind, e.Entry}] = e
+	}
+	var extra []*entryCoverage
+	match := func(k key) {
+		e := byKey[k]
+		if e == nil {
+			e = &entryCoverage{List: k.list, Kind: k.kind, Entry: k.entry}
+			byKey[k] = e
+			extra = append(extra, e)
+		}
+		e.Matches++
+	}
+	for _, pr := range userPackages(results) {
+		if pr.result == nil {
+			continue
+		}
+		for _, ir := range pr.result.Imports {
+			if ir.AllowEntry != "" {
+				match(key{ir.List, "allow", ir.AllowEntry})
+			}
+			if ir.DenyEntry != "" {
+				match(key{ir.List, "deny", ir.DenyEntry})
+			}
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool {
+		if extra[i].List != extra[j].List {
+			return extra[i].List < extra[j].List
+		}
+		if extra[i].Kind != extra[j].Kind {
+			return extra[i].Kind < extra[j].Kind
+		}
+		return extra[i].Entry < extra[j].Entry
+	})
+	return append(entries, extra...)
+}
+
+type coverageWriter interface {
+	write(io.Writer, []*entryCoverage) error
+}
+
+// coverageNote says which rules coverage counts, so that a list with only other rules
+// does not look fully covered.
+const coverageNote = "Only the allow and deny entries are counted, not the other rules of the lists."
+
+type textCoverageWriter struct{}
+
+func (*textCoverageWriter) write(w io.Writer, entries []*entryCoverage) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\n\n", coverageNote)
+	fmt.Fprintln(tw, "LIST\tKIND\tMATCHES\tENTRY")
+	var unused []*entryCoverage
+	for _, e := range entries {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", e.List, e.Kind, e.Matches, e.Entry)
+		if e.Matches == 0 {
+			unused = append(unused, e)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(unused) == 0 {
+		return nil
+	}
+	fmt.Fprintln(tw, "\nNever matched:")
+	for _, e := range unused {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", e.List, e.Kind, e.Entry)
+	}
+	return tw.Flush()
+}
+
+type jsonCoverageWriter struct{}
+
+func (*jsonCoverageWriter) write(w io.Writer, entries []*entryCoverage) error {
+	if entries == nil {
+		entries = []*entryCoverage{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// coverageMain runs the coverage command with its arguments, returning the exit code.
+func coverageMain(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("depguard coverage", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "text", "output `format` of the report: text or json")
+	tests := fs.Bool("test", true, "include test packages")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: depguard coverage [-format text|json] [-test=false] [packages]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	cw, ok := coverageFormats[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown coverage format %q\n", *format)
+		return 2
+	}
+	settings := loadSettings(stderr)
+	entries := configEntries(settings)
+	analyzer, err := depguard.NewAnalyzer(settings)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	results, err := analyze(analyzer, *tests, patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if err := cw.write(stdout, countMatches(entries, results)); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
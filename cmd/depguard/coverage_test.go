@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/OpenPeeDeeP/depguard/v2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCountMatches(t *testing.T) {
+	settings := &depguard.LinterSettings{
+		"Main": &depguard.List{
+			Allow: []string{"$gostd", "github.com/stale"},
+			Deny:  map[string]string{"reflect": "no", "encoding/gob": "no"},
+		},
+		"Tests": &depguard.List{
+			Deny: map[string]string{"github.com/stretchr/testify": "use testing"},
+		},
+		"NoCgo": &depguard.List{
+			Deny: map[string]string{"$cgo": "keep builds static"},
+		},
+	}
+	// Only the test variant of the app is counted as it has the files of the package too
+	expected := []*entryCoverage{
+		{List: "Main", Kind: "allow", Entry: "$gostd", Matches: 5},
+		{List: "Main", Kind: "allow", Entry: "github.com/stale"},
+		{List: "Main", Kind: "deny", Entry: "encoding/gob"},
+		{List: "Main", Kind: "deny", Entry: "reflect", Matches: 1},
+		{List: "NoCgo", Kind: "deny", Entry: "$cgo", Matches: 1},
+		{List: "Tests", Kind: "deny", Entry: "github.com/stretchr/testify", Matches: 1},
+		{List: "Other", Kind: "allow", Entry: "os", Matches: 1},
+	}
+	act := countMatches(configEntries(settings), testResults("repo"))
+	if diff := cmp.Diff(expected, act); diff != "" {
+		t.Errorf("Coverage did not match expected\n%s", diff)
+	}
+}
+
+func TestTextCoverageWriter(t *testing.T) {
+	var buf bytes.Buffer
+	err := coverageFormats["text"].write(&buf, []*entryCoverage{
+		{List: "Main", Kind: "allow", Entry: "$gostd", Matches: 12},
+		{List: "Main", Kind: "deny", Entry: "encoding/gob"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := `Only the allow and deny entries are counted, not the other rules of the lists.
+
+LIST  KIND   MATCHES  ENTRY
+Main  allow  12       $gostd
+Main  deny   0        encoding/gob
+
+Never matched:
+  Main  deny  encoding/gob
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("Output did not match expected\n%s", diff)
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	depguard "github.com/OpenPeeDeeP/depguard/v2"
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	newFromRevFlag   = "new-from-rev"
+	newFromPatchFlag = "new-from-patch"
+)
+
+// fileChanges holds what a diff changes in a file.
+type fileChanges struct {
+	// added holds the lines the diff adds.
+	added map[int]bool
+	// all is set for files git doesn't track, every line of which is new.
+	all bool
+}
+
+// changedLines holds the changes of a diff by the absolute name of the file.
+type changedLines map[string]*fileChanges
+
+// contains reports whether the position is on a line the diff added. The names git
+// gives have their symbolic links resolved, so the file is also looked up by the name
+// it resolves to, as the names of a checkout reached through a link don't.
+func (c changedLines) contains(pos token.Position) bool {
+	fc := c[filepath.Clean(pos.Filename)]
+	if fc == nil {
+		if name, err := filepath.EvalSymlinks(pos.Filename); err == nil {
+			fc = c[name]
+		}
+	}
+	return fc != nil && (fc.all || fc.added[pos.Line])
+}
+
+// isNew reports whether a violation is new, which it is when it is on an added line
+// or any of what makes it up is, like an import that is counted against a budget.
+func (c changedLines) isNew(fset *token.FileSet, pos token.Pos, related []token.Pos) bool {
+	if c.contains(fset.Position(pos)) {
+		return true
+	}
+	for _, r := range related {
+		if c.contains(fset.Position(r)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDiff reads the lines changed by a unified diff. The names in the diff are
+// relative to root.
+func parseDiff(r io.Reader, root string) (changedLines, error) {
+	changes := make(changedLines)
+	var fc *fileChanges
+	// line is the next line of the new file, oldLeft and newLeft are how many lines
+	// of the old and new file the hunk still has. File headers are only looked for
+	// once the hunk is used up, as removed or added lines can look like them.
+	var line, oldLeft, newLeft int
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(text, "+"):
+				if fc != nil {
+					fc.added[line] = true
+				}
+				line++
+				newLeft--
+			case strings.HasPrefix(text, "-"):
+				oldLeft--
+			case strings.HasPrefix(text, `\`):
+				// \ No newline at end of file
+			default:
+				// Context lines, which some tools strip the leading space of when empty
+				line++
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+		switch {
+		case strings.HasPrefix(text, "--- "):
+			fc = nil
+		case strings.HasPrefix(text, "+++ "):
+			name := strings.TrimSpace(strings.TrimPrefix(text, "+++ "))
+			if tab := strings.IndexByte(name, '\t'); tab != -1 {
+				name = name[:tab]
+			}
+			fc = nil
+			if name == "/dev/null" {
+				continue
+			}
+			name = filepath.Clean(filepath.Join(root, filepath.FromSlash(strings.TrimPrefix(name, "b/"))))
+			if changes[name] == nil {
+				changes[name] = &fileChanges{added: make(map[int]bool)}
+			}
+			fc = changes[name]
+		case strings.HasPrefix(text, "@@ "):
+			var err error
+			line, oldLeft, newLeft, err = parseHunkHeader(text)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read diff: %w", err)
+	}
+	return changes, nil
+}
+
+// parseHunkHeader reads a hunk header, @@ -a,b +c,d @@, returning where the new lines
+// start and how many old and new lines the hunk has. A missing count is one.
+func parseHunkHeader(text string) (start, oldCount, newCount int, err error) {
+	fields := strings.Fields(text)
+	if len(fields) < 4 || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") || fields[3] != "@@" {
+		return 0, 0, 0, fmt.Errorf("could not parse hunk header %q", text)
+	}
+	_, oldCount, err = parseRange(fields[1][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not parse hunk header %q: %w", text, err)
+	}
+	start, newCount, err = parseRange(fields[2][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("could not parse hunk header %q: %w", text, err)
+	}
+	return start, oldCount, newCount, nil
+}
+
+// parseRange reads the start,count range of a hunk header.
+func parseRange(r string) (start, count int, err error) {
+	s, c, found := strings.Cut(r, ",")
+	if start, err = strconv.Atoi(s); err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return start, 1, nil
+	}
+	if count, err = strconv.Atoi(c); err != nil {
+		return 0, 0, err
+	}
+	return start, count, nil
+}
+
+// gitRoot returns the top level directory of the git repository depguard is run in.
+func gitRoot() (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return "", fmt.Errorf("could not find the git repository: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// loadChanges returns the lines added since the git revision, or by the patch file when
+// no revision is given. It returns nil when neither is, so nothing should be filtered.
+func loadChanges(rev, patch string) (changedLines, error) {
+	switch {
+	case rev != "":
+		root, err := gitRoot()
+		if err != nil {
+			return nil, err
+		}
+		out, err := exec.Command("git", "-C", root, "diff", "--no-color", "--no-ext-diff", "-U0", rev, "--").Output()
+		if err != nil {
+			return nil, fmt.Errorf("could not diff against %s: %w", rev, err)
+		}
+		changes, err := parseDiff(bytes.NewReader(out), root)
+		if err != nil {
+			return nil, err
+		}
+		// Files that were never added to git are not in the diff, but all of them is new
+		out, err = exec.Command("git", "-C", root, "ls-files", "--others", "--exclude-standard", "-z").Output()
+		if err != nil {
+			return nil, fmt.Errorf("could not list untracked files: %w", err)
+		}
+		for _, name := range strings.Split(string(out), "\x00") {
+			if name != "" {
+				changes[filepath.Clean(filepath.Join(root, filepath.FromSlash(name)))] = &fileChanges{all: true}
+			}
+		}
+		return changes, nil
+	case patch != "":
+		f, err := os.Open(patch)
+		if err != nil {
+			return nil, fmt.Errorf("could not open patch: %w", err)
+		}
+		defer f.Close()
+		// Patches made with git diff are relative to the top of the repository
+		root, err := gitRoot()
+		if err != nil {
+			root = "."
+		}
+		if root, err = filepath.Abs(root); err != nil {
+			return nil, err
+		}
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		return parseDiff(f, root)
+	default:
+		return nil, nil
+	}
+}
+
+// onlyNew makes the analyzer only report violations on lines added since a git
+// revision or by a patch file, set through its flags.
+func onlyNew(a *analysis.Analyzer) {
+	rev := a.Flags.String(newFromRevFlag, "", "only report violations on lines added since the git `revision`")
+	patch := a.Flags.String(newFromPatchFlag, "", "only report violations on lines added by the unified diff in `file`")
+	var once sync.Once
+	var changes changedLines
+	var changesErr error
+	run := a.Run
+	a.Run = func(pass *analysis.Pass) (interface{}, error) {
+		once.Do(func() {
+			changes, changesErr = loadChanges(*rev, *patch)
+		})
+		if changesErr != nil {
+			return nil, changesErr
+		}
+		if changes == nil {
+			return run(pass)
+		}
+		filtered := *pass
+		filtered.Report = func(d analysis.Diagnostic) {
+			related := make([]token.Pos, 0, len(d.Related))
+			for _, r := range d.Related {
+				related = append(related, r.Pos)
+			}
+			if changes.isNew(pass.Fset, d.Pos, related) {
+				pass.Report(d)
+			}
+		}
+		res, err := run(&filtered)
+		if r, ok := res.(*depguard.Result); ok {
+			violations := r.Violations[:0]
+			for _, v := range r.Violations {
+				if changes.isNew(pass.Fset, v.Pos, v.Related) {
+					violations = append(violations, v)
+				}
+			}
+			r.Violations = violations
+		}
+		return res, err
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDiff = `diff --git a/a.go b/a.go
+index 49d67ed..64d4273 100644
+--- a/a.go
++++ b/a.go
+@@ -1,3 +1,6 @@
+ package nr
+ 
+-import _ "reflect"
++import (
++	_ "reflect"
++	_ "unsafe"
++)
+diff --git a/pkg/b.go b/pkg/b.go
+new file mode 100644
+--- /dev/null
++++ b/pkg/b.go
+@@ -0,0 +1,3 @@
++package pkg
++
++import "os"
+diff --git a/c.go b/c.go
+deleted file mode 100644
+--- a/c.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package nr
+diff --git a/d.go b/d.go
+--- a/d.go
++++ b/d.go
+@@ -1,3 +1,3 @@
+--- a comment that looked like a file header
++++ a line that looked like a file header
+ package d
+-var x = 1
++var x = 2
+diff --git a/e.go b/e.go
+--- a/e.go
++++ b/e.go
+@@ -5 +5,2 @@ func f() {
+-	return
++	x++
++	return
+`
+
+func TestParseDiff(t *testing.T) {
+	root, err := filepath.Abs("repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := parseDiff(strings.NewReader(testDiff), root)
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	tests := []struct {
+		file string
+		line int
+		exp  bool
+	}{
+		{file: "a.go", line: 1, exp: false},
+		{file: "a.go", line: 3, exp: true},
+		{file: "a.go", line: 4, exp: true},
+		{file: "a.go", line: 5, exp: true},
+		{file: "pkg/b.go", line: 3, exp: true},
+		{file: "c.go", line: 1, exp: false},
+		{file: "d.go", line: 1, exp: true},
+		{file: "d.go", line: 2, exp: false},
+		{file: "d.go", line: 3, exp: true},
+		{file: "e.go", line: 5, exp: true},
+		{file: "e.go", line: 6, exp: true},
+		{file: "e.go", line: 7, exp: false},
+		{file: "x.go", line: 1, exp: false},
+	}
+	for _, tc := range tests {
+		pos := token.Position{Filename: filepath.Join(root, filepath.FromSlash(tc.file)), Line: tc.line}
+		if act := changes.contains(pos); act != tc.exp {
+			t.Errorf("%s:%d did not match expected: Exp %t: Act: %t", tc.file, tc.line, tc.exp, act)
+		}
+	}
+}
+
+func TestParseDiffBadHunk(t *testing.T) {
+	_, err := parseDiff(strings.NewReader("+++ b/a.go\n@@ -1 x @@\n"), ".")
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+// testRepo makes a git repository with a committed file that has since been changed,
+// an untracked file and an ignored one.
+func testRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=depguard", "-c", "user.email=depguard@example.com"}, args...)...)
+		cmd.Dir = root
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %s\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	write := func(name, text string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("a.go", "package a\n")
+	write(".gitignore", "ignored.go\n")
+	git("add", "a.go", ".gitignore")
+	git("commit", "-q", "-m", "init")
+	write("a.go", "package a\n\nimport \"os\"\n")
+	write("new.go", "package a\n\nimport \"reflect\"\n")
+	write("ignored.go", "package a\n")
+	return root
+}
+
+func TestLoadChanges(t *testing.T) {
+	scenarios := []struct {
+		name string
+		// dir returns the directory to run in, given the repository
+		dir func(t *testing.T, root string) string
+	}{
+		{
+			name: "Repository",
+			dir:  func(_ *testing.T, root string) string { return root },
+		},
+		{
+			// git resolves the link, while the names of the files don't
+			name: "Symlink To Repository",
+			dir: func(t *testing.T, root string) string {
+				link := filepath.Join(t.TempDir(), "link")
+				if err := os.Symlink(root, link); err != nil {
+					t.Skip("could not make a symbolic link:", err)
+				}
+				return link
+			},
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			dir := s.dir(t, testRepo(t))
+			chdir(t, dir)
+			changes, err := loadChanges("HEAD", "")
+			if err != nil {
+				t.Fatal("not expecting an error", err)
+			}
+			tests := []struct {
+				file string
+				line int
+				exp  bool
+			}{
+				{file: "a.go", line: 1, exp: false},
+				{file: "a.go", line: 3, exp: true},
+				{file: "new.go", line: 1, exp: true},
+				{file: "new.go", line: 3, exp: true},
+				{file: "ignored.go", line: 1, exp: false},
+			}
+			for _, tc := range tests {
+				pos := token.Position{Filename: filepath.Join(dir, tc.file), Line: tc.line}
+				if act := changes.contains(pos); act != tc.exp {
+					t.Errorf("%s:%d did not match expected: Exp %t: Act: %t", tc.file, tc.line, tc.exp, act)
+				}
+			}
+		})
+	}
+}
+
+func TestChangedLinesIsNew(t *testing.T) {
+	fset := token.NewFileSet()
+	f := fset.AddFile(filepath.Join("repo", "a.go"), -1, 100)
+	f.SetLines([]int{0, 10, 20, 30})
+	changes := changedLines{filepath.Join("repo", "a.go"): &fileChanges{added: map[int]bool{3: true}}}
+	if !changes.isNew(fset, f.Pos(25), nil) {
+		t.Error("expected a violation on an added line to be new")
+	}
+	if changes.isNew(fset, f.Pos(5), []token.Pos{f.Pos(15)}) {
+		t.Error("expected a violation without anything on added lines not to be new")
+	}
+	if !changes.isNew(fset, f.Pos(5), []token.Pos{f.Pos(15), f.Pos(25)}) {
+		t.Error("expected a violation with a related position on an added line to be new")
+	}
+}
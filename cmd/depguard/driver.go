@@ -0,0 +1,136 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+
+	depguard "github.com/OpenPeeDeeP/depguard/v2"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/packages"
+)
+
+// packageResult is what running the analyzer on one of the packages asked for gave.
+type packageResult struct {
+	pkg         *packages.Package
+	result      *depguard.Result
+	diagnostics []analysis.Diagnostic
+}
+
+// analyze loads the packages matching the patterns and runs the analyzer on them.
+// It is a small driver for the commands that need the results of the analyzer,
+// which the analysis drivers of x/tools don't hand back.
+func analyze(a *analysis.Analyzer, tests bool, patterns []string) ([]*packageResult, error) {
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+		packages.NeedTypes | packages.NeedTypesSizes | packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedModule
+	if len(a.FactTypes) > 0 {
+		// Facts flow from dependencies, so they need to be analyzed too
+		mode |= packages.NeedDeps
+	}
+	pkgs, err := packages.Load(&packages.Config{Mode: mode, Tests: tests}, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("could not load packages: %w", err)
+	}
+	var errs []string
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		for _, e := range pkg.Errors {
+			errs = append(errs, e.Error())
+		}
+	})
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+
+	roots := make(map[*packages.Package]bool, len(pkgs))
+	for _, pkg := range pkgs {
+		roots[pkg] = true
+	}
+	facts := make(map[factKey]analysis.Fact)
+	var results []*packageResult
+	var runErr error
+	packages.Visit(pkgs, nil, func(pkg *packages.Package) {
+		if runErr != nil || (!roots[pkg] && len(a.FactTypes) == 0) {
+			return
+		}
+		pr := &packageResult{pkg: pkg}
+		pass := &analysis.Pass{
+			Analyzer:     a,
+			Fset:         pkg.Fset,
+			Files:        pkg.Syntax,
+			OtherFiles:   pkg.OtherFiles,
+			IgnoredFiles: pkg.IgnoredFiles,
+			Pkg:          pkg.Types,
+			TypesInfo:    pkg.TypesInfo,
+			TypesSizes:   pkg.TypesSizes,
+			ResultOf:     map[*analysis.Analyzer]interface{}{},
+			Report: func(d analysis.Diagnostic) {
+				pr.diagnostics = append(pr.diagnostics, d)
+			},
+			ImportPackageFact: func(imported *types.Package, fact analysis.Fact) bool {
+				f, ok := facts[factKey{pkg: imported, t: reflect.TypeOf(fact)}]
+				if ok {
+					reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(f).Elem())
+				}
+				return ok
+			},
+			ExportPackageFact: func(fact analysis.Fact) {
+				facts[factKey{pkg: pkg.Types, t: reflect.TypeOf(fact)}] = fact
+			},
+			ImportObjectFact: func(types.Object, analysis.Fact) bool { return false },
+			ExportObjectFact: func(types.Object, analysis.Fact) {},
+			AllPackageFacts:  func() []analysis.PackageFact { return nil },
+			AllObjectFacts:   func() []analysis.ObjectFact { return nil },
+		}
+		res, err := a.Run(pass)
+		if err != nil {
+			runErr = fmt.Errorf("%s: %w", pkg.ID, err)
+			return
+		}
+		if !roots[pkg] {
+			return
+		}
+		pr.result, _ = res.(*depguard.Result)
+		results = append(results, pr)
+	})
+	if runErr != nil {
+		return nil, runErr
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].pkg.ID < results[j].pkg.ID
+	})
+	return results, nil
+}
+
+// userPackages keeps the results of the code people wrote, dropping the test mains go
+// generates and the packages whose test variant was analyzed too, as that variant
+// has all of their files.
+func userPackages(results []*packageResult) []*packageResult {
+	variants := make(map[string]bool)
+	for _, pr := range results {
+		if isTestVariant(pr) {
+			variants[pr.pkg.PkgPath] = true
+		}
+	}
+	var user []*packageResult
+	for _, pr := range results {
+		if strings.HasSuffix(pr.pkg.PkgPath, ".test") || (!isTestVariant(pr) && variants[pr.pkg.PkgPath]) {
+			continue
+		}
+		user = append(user, pr)
+	}
+	return user
+}
+
+// isTestVariant reports whether the package is compiled with the test files of a package.
+func isTestVariant(pr *packageResult) bool {
+	return strings.Contains(pr.pkg.ID, " [")
+}
+
+// factKey identifies the fact of a type a package exported.
+type factKey struct {
+	pkg *types.Package
+	t   reflect.Type
+}
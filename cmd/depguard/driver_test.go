@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/OpenPeeDeeP/depguard/v2"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/tools/go/packages"
+)
+
+// testResults are the results of checking a module in wd with an app package that
+// introduces cgo and has tests, and a lib package. Like analyze gives them with tests,
+// they include the app package, its test variant and the test main go generates.
+func testResults(wd string) []*packageResult {
+	fset := token.NewFileSet()
+	aName := filepath.ToSlash(filepath.Join(wd, "app", "a.go"))
+	a := fset.AddFile(aName, -1, 100)
+	a.SetLines([]int{0, 20, 40, 60})
+	aTestName := filepath.ToSlash(filepath.Join(wd, "app", "a_test.go"))
+	aTest := fset.AddFile(aTestName, -1, 100)
+	aTest.SetLines([]int{0, 20, 40})
+	lName := filepath.ToSlash(filepath.Join(wd, "lib", "l.go"))
+	fset.AddFile(lName, -1, 100).SetLines([]int{0, 20})
+
+	pkgs := make(map[string]*packages.Package)
+	for _, path := range []string{"fmt", "os", "reflect", "strings", "testing", "github.com/stretchr/testify/assert"} {
+		pkgs[path] = &packages.Package{ID: path, PkgPath: path}
+	}
+	imports := func(paths ...string) map[string]*packages.Package {
+		m := make(map[string]*packages.Package, len(paths))
+		for _, path := range paths {
+			m[path] = pkgs[path]
+		}
+		return m
+	}
+	app := &packages.Package{ID: "example.com/app", PkgPath: "example.com/app", Fset: fset,
+		Imports: imports("fmt", "os", "reflect", "strings")}
+	appTest := &packages.Package{ID: "example.com/app [example.com/app.test]", PkgPath: "example.com/app", Fset: fset,
+		Imports: imports("fmt", "os", "reflect", "strings", "testing", "github.com/stretchr/testify/assert")}
+	lib := &packages.Package{ID: "example.com/lib", PkgPath: "example.com/lib", Fset: fset,
+		Imports: imports("fmt", "os")}
+	testMain := &packages.Package{ID: "example.com/app.test", PkgPath: "example.com/app.test", Fset: fset,
+		Imports: map[string]*packages.Package{"example.com/app": appTest, "os": pkgs["os"], "testing": pkgs["testing"]}}
+
+	appImports := func() []*depguard.ImportResult {
+		return []*depguard.ImportResult{
+			{File: aName, Importer: "example.com/app", Import: "C", List: "NoCgo", DenyEntry: "$cgo", Rule: "$cgo"},
+			{File: aName, Importer: "example.com/app", Import: "fmt", List: "Main", Allowed: true, AllowEntry: "$gostd", Rule: "$gostd"},
+			{File: aName, Importer: "example.com/app", Import: "fmt", List: "Other", Allowed: true},
+			{File: aName, Importer: "example.com/app", Import: "os", List: "Main", Allowed: true, AllowEntry: "$gostd", Rule: "$gostd"},
+			{File: aName, Importer: "example.com/app", Import: "reflect", List: "Main", AllowEntry: "$gostd", DenyEntry: "reflect", Rule: "reflect"},
+		}
+	}
+	appViolations := func() []*depguard.Violation {
+		return []*depguard.Violation{
+			{
+				Pos:      a.Pos(27),
+				File:     aName,
+				Import:   "C",
+				Kind:     "cgo",
+				List:     "NoCgo",
+				Rule:     "$cgo",
+				Severity: "error",
+				Message:  "cgo import 'C' is not allowed from list 'NoCgo'",
+			},
+			{
+				Pos:        a.Pos(42),
+				File:       aName,
+				Import:     "reflect",
+				List:       "Main",
+				Mode:       "Strict",
+				Rule:       "reflect",
+				Suggestion: "Who needs reflection",
+				Severity:   "error",
+				Owner:      "core-team",
+				Message:    "import 'reflect' is not allowed from list 'Main': Who needs reflection",
+			},
+			{
+				Pos:      a.Pos(62),
+				File:     aName,
+				Import:   "os",
+				Kind:     "deprecated",
+				List:     "Other",
+				Severity: "warning",
+				Message:  "deprecated import 'os' is not allowed from list 'Other'",
+			},
+		}
+	}
+	return []*packageResult{
+		{pkg: app, result: &depguard.Result{
+			CgoFiles:   []string{aName},
+			Imports:    appImports(),
+			Violations: appViolations(),
+		}},
+		{pkg: appTest, result: &depguard.Result{
+			CgoFiles: []string{aName},
+			Imports: append(appImports(),
+				&depguard.ImportResult{File: aTestName, Importer: "example.com/app", Import: "testing", List: "Main", Allowed: true, AllowEntry: "$gostd", Rule: "$gostd"},
+				&depguard.ImportResult{File: aTestName, Importer: "example.com/app", Import: "github.com/stretchr/testify/assert", List: "Tests", DenyEntry: "github.com/stretchr/testify", Rule: "github.com/stretchr/testify"},
+			),
+			Violations: append(appViolations(),
+				&depguard.Violation{
+					Pos:      aTest.Pos(25),
+					File:     aTestName,
+					Import:   "github.com/stretchr/testify/assert",
+					List:     "Tests",
+					Mode:     "Original",
+					Rule:     "github.com/stretchr/testify",
+					Severity: "warning",
+					Message:  "import 'github.com/stretchr/testify/assert' is not allowed from list 'Tests'",
+				},
+				// Import budgets are about the package, so they have no import
+				&depguard.Violation{
+					Pos:      a.Pos(0),
+					File:     aName,
+					Kind:     "imports",
+					List:     "Tests",
+					Severity: "warning",
+					Message:  "package 'example.com/app' imports 6 packages, more than the 5 list 'Tests' allows",
+				},
+			),
+		}},
+		{pkg: lib, result: &depguard.Result{
+			CgoFiles: []string{lName},
+			Imports: []*depguard.ImportResult{
+				{File: lName, Importer: "example.com/lib", Import: "fmt", List: "Main", Allowed: true, AllowEntry: "$gostd", Rule: "$gostd"},
+				{File: lName, Importer: "example.com/lib", Import: "os", List: "Other", Allowed: true, AllowEntry: "os", Rule: "os"},
+			},
+		}},
+		{pkg: testMain, result: &depguard.Result{
+			Imports: []*depguard.ImportResult{
+				{Importer: "example.com/app.test", Import: "os", List: "Main", Allowed: true, AllowEntry: "$gostd", Rule: "$gostd"},
+			},
+			Violations: []*depguard.Violation{
+				{Import: "os", Kind: "deprecated", List: "Other", Severity: "warning"},
+			},
+		}},
+	}
+}
+
+func TestUserPackages(t *testing.T) {
+	var act []string
+	for _, pr := range userPackages(testResults("repo")) {
+		act = append(act, pr.pkg.ID)
+	}
+	expected := []string{"example.com/app [example.com/app.test]", "example.com/lib"}
+	if diff := cmp.Diff(expected, act); diff != "" {
+		t.Errorf("User packages did not match expected\n%s", diff)
+	}
+}
+
+// chdir changes the working directory to dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestAnalyze(t *testing.T) {
+	root := t.TempDir()
+	for name, text := range map[string]string{
+		"go.mod":              "module example.com/app\n\ngo 1.21\n",
+		"app.go":              "package app\n\nimport \"reflect\"\n\nvar _ = reflect.TypeOf(0)\n",
+		"app_test.go":         "package app\n\nimport \"testing\"\n\nfunc TestApp(t *testing.T) {}\n",
+		"lib/lib.go":          "package lib\n\nimport \"os\"\n\nvar _ = os.Args\n",
+		"lib/internal/i.go":   "package internal\n",
+		"testdata/bad/bad.go": "package bad\n\nimport \"reflect\"\n",
+	} {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(text), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	chdir(t, root)
+	t.Setenv("GOFLAGS", "")
+	a, err := depguard.NewAnalyzer(&depguard.LinterSettings{"Main": &depguard.List{
+		Deny: map[string]string{"reflect": "Who needs reflection"},
+	}})
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	results, err := analyze(a, true, []string{"./..."})
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	type summary struct {
+		ID          string
+		Violations  int
+		Diagnostics []string
+	}
+	var act []summary
+	for _, pr := range results {
+		if pr.result == nil {
+			t.Fatalf("%s has no result", pr.pkg.ID)
+		}
+		s := summary{ID: pr.pkg.ID, Violations: len(pr.result.Violations)}
+		for _, d := range pr.diagnostics {
+			pos := pr.pkg.Fset.Position(d.Pos)
+			s.Diagnostics = append(s.Diagnostics, fmt.Sprintf("%s:%d:%d: %s", filepath.Base(pos.Filename), pos.Line, pos.Column, d.Message))
+		}
+		act = append(act, s)
+	}
+	// The packages in testdata are left out by ./... like go build does
+	reflectDiagnostic := "app.go:3:8: import 'reflect' is not allowed from list 'Main': Who needs reflection"
+	expected := []summary{
+		{ID: "example.com/app", Violations: 1, Diagnostics: []string{reflectDiagnostic}},
+		{ID: "example.com/app [example.com/app.test]", Violations: 1, Diagnostics: []string{reflectDiagnostic}},
+		{ID: "example.com/app.test"},
+		{ID: "example.com/app/lib"},
+		{ID: "example.com/app/lib/internal"},
+	}
+	if diff := cmp.Diff(expected, act); diff != "" {
+		t.Errorf("Results did not match expected\n%s", diff)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	verdictAllowed   = "allowed"
+	verdictDenied    = "denied"
+	verdictUnchecked = "unchecked"
+)
+
+// verdictColors are the colors edges are drawn in for their verdict.
+var verdictColors = map[string]string{
+	verdictAllowed:   "green",
+	verdictDenied:    "red",
+	verdictUnchecked: "gray",
+}
+
+var (
+	graphFormats = map[string]graphWriter{
+		"dot":     &dotGraphWriter{},
+		"mermaid": &mermaidGraphWriter{},
+		"json":    &jsonGraphWriter{},
+	}
+)
+
+// graph is the import graph of the packages that were checked.
+type graph struct {
+	Nodes []string     `json:"nodes"`
+	Edges []*graphEdge `json:"edges"`
+}
+
+// graphEdge is an import of one package by another with what the lists decided about it.
+type graphEdge struct {
+	From    string       `json:"from"`
+	To      string       `json:"to"`
+	Verdict string       `json:"verdict"`
+	Lists   []*graphList `json:"lists,omitempty"`
+}
+
+// graphList is the verdict of a list on an edge.
+type graphList struct {
+	List    string `json:"list"`
+	Allowed bool   `json:"allowed"`
+	Rule    string `json:"rule,omitempty"`
+	// Kind is the kind of the violation when a check other than the allow and deny
+	// entries denied the edge, see .Kind for messages.
+	Kind string `json:"kind,omitempty"`
+	// Symbol is the denied symbol when the edge was denied for using it.
+	Symbol string `json:"symbol,omitempty"`
+}
+
+// label describes the lists that decided an edge, the ones that denied it if any did.
+func (e *graphEdge) label() string {
+	var parts []string
+	for _, l := range e.Lists {
+		if e.Verdict == verdictDenied && l.Allowed {
+			continue
+		}
+		part := l.List
+		if l.Kind != "" {
+			part += " (" + l.Kind + ")"
+		}
+		if l.Rule != "" {
+			part += ": " + l.Rule
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildGraph makes the import graph of the checked packages, deciding every edge from
+// the verdicts of the lists on the imports and the violations of their other checks.
+// An edge is denied when any list denied it in any file, and unchecked when no list
+// looked at it.
+func buildGraph(results []*packageResult) *graph {
+	edges := make(map[[2]string]*graphEdge)
+	nodes := make(map[string]bool)
+	for _, pr := range userPackages(results) {
+		from := pr.pkg.PkgPath
+		nodes[from] = true
+		edge := func(to string) *graphEdge {
+			key := [2]string{from, to}
+			if edges[key] == nil {
+				nodes[to] = true
+				edges[key] = &graphEdge{From: from, To: to, Verdict: verdictUnchecked}
+			}
+			return edges[key]
+		}
+		for path := range pr.pkg.Imports {
+			edge(path)
+		}
+		if pr.result == nil {
+			continue
+		}
+		for _, ir := range pr.result.Imports {
+			e := edge(ir.Import)
+			e.addList(&graphList{List: ir.List, Allowed: ir.Allowed, Rule: ir.Rule})
+			switch {
+			case !ir.Allowed:
+				e.Verdict = verdictDenied
+			case e.Verdict == verdictUnchecked:
+				e.Verdict = verdictAllowed
+			}
+		}
+		for _, v := range pr.result.Violations {
+			if v.Import == "" {
+				// Import budgets are about the package, not one of its imports
+				continue
+			}
+			e := edge(v.Import)
+			e.addList(&graphList{List: v.List, Rule: v.Rule, Kind: v.Kind, Symbol: v.Symbol})
+			e.Verdict = verdictDenied
+		}
+	}
+	g := &graph{Nodes: make([]string, 0, len(nodes)), Edges: make([]*graphEdge, 0, len(edges))}
+	for n := range nodes {
+		g.Nodes = append(g.Nodes, n)
+	}
+	sort.Strings(g.Nodes)
+	for _, e := range edges {
+		sort.Slice(e.Lists, func(i, j int) bool {
+			a, b := e.Lists[i], e.Lists[j]
+			if a.List != b.List {
+				return a.List < b.List
+			}
+			if a.Kind != b.Kind {
+				return a.Kind < b.Kind
+			}
+			if a.Rule != b.Rule {
+				return a.Rule < b.Rule
+			}
+			return a.Symbol < b.Symbol
+		})
+		g.Edges = append(g.Edges, e)
+	}
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	return g
+}
+
+// addList adds the verdict of a list to the edge unless it already has the same one.
+func (e *graphEdge) addList(gl *graphList) {
+	for _, l := range e.Lists {
+		if *l == *gl {
+			return
+		}
+	}
+	e.Lists = append(e.Lists, gl)
+}
+
+type graphWriter interface {
+	write(io.Writer, *graph) error
+}
+
+type dotGraphWriter struct{}
+
+func (*dotGraphWriter) write(w io.Writer, g *graph) error {
+	var b strings.Builder
+	b.WriteString("digraph depguard {\n\tnode [shape=box];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "\t%s;\n", dotQuote(n))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%s -> %s [color=%s", dotQuote(e.From), dotQuote(e.To), verdictColors[e.Verdict])
+		if label := e.label(); label != "" {
+			fmt.Fprintf(&b, ", label=%s", dotQuote(label))
+		}
+		b.WriteString("];\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+type mermaidGraphWriter struct{}
+
+func (*mermaidGraphWriter) write(w io.Writer, g *graph) error {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+		fmt.Fprintf(&b, "    %s[%s]\n", ids[n], mermaidQuote(n))
+	}
+	for _, e := range g.Edges {
+		if label := e.label(); label != "" {
+			fmt.Fprintf(&b, "    %s -->|%s| %s\n", ids[e.From], mermaidQuote(label), ids[e.To])
+			continue
+		}
+		fmt.Fprintf(&b, "    %s --> %s\n", ids[e.From], ids[e.To])
+	}
+	for i, e := range g.Edges {
+		fmt.Fprintf(&b, "    linkStyle %d stroke:%s\n", i, verdictColors[e.Verdict])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+type jsonGraphWriter struct{}
+
+func (*jsonGraphWriter) write(w io.Writer, g *graph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// graphMain runs the graph command with its arguments, returning the exit code.
+func graphMain(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("depguard graph", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "dot", "output `format` of the graph: dot, mermaid or json")
+	tests := fs.Bool("test", true, "include test packages")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: depguard graph [-format dot|mermaid|json] [-test=false] [packages]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	gw, ok := graphFormats[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown graph format %q\n", *format)
+		return 2
+	}
+	analyzer, err := loadAnalyzer(stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	results, err := analyze(analyzer, *tests, patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if err := gw.write(stdout, buildGraph(results)); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildGraph(t *testing.T) {
+	expected := &graph{
+		Nodes: []string{
+			"C",
+			"example.com/app",
+			"example.com/lib",
+			"fmt",
+			"github.com/stretchr/testify/assert",
+			"os",
+			"reflect",
+			"strings",
+			"testing",
+		},
+		Edges: []*graphEdge{
+			{From: "example.com/app", To: "C", Verdict: verdictDenied, Lists: []*graphList{
+				{List: "NoCgo", Allowed: false, Rule: "$cgo"},
+				{List: "NoCgo", Rule: "$cgo", Kind: "cgo"},
+			}},
+			{From: "example.com/app", To: "fmt", Verdict: verdictAllowed, Lists: []*graphList{
+				{List: "Main", Allowed: true, Rule: "$gostd"},
+				{List: "Other", Allowed: true},
+			}},
+			{From: "example.com/app", To: "github.com/stretchr/testify/assert", Verdict: verdictDenied, Lists: []*graphList{
+				{List: "Tests", Allowed: false, Rule: "github.com/stretchr/testify"},
+			}},
+			{From: "example.com/app", To: "os", Verdict: verdictDenied, Lists: []*graphList{
+				{List: "Main", Allowed: true, Rule: "$gostd"},
+				{List: "Other", Kind: "deprecated"},
+			}},
+			{From: "example.com/app", To: "reflect", Verdict: verdictDenied, Lists: []*graphList{
+				{List: "Main", Allowed: false, Rule: "reflect"},
+			}},
+			{From: "example.com/app", To: "strings", Verdict: verdictUnchecked},
+			{From: "example.com/app", To: "testing", Verdict: verdictAllowed, Lists: []*graphList{
+				{List: "Main", Allowed: true, Rule: "$gostd"},
+			}},
+			{From: "example.com/lib", To: "fmt", Verdict: verdictAllowed, Lists: []*graphList{
+				{List: "Main", Allowed: true, Rule: "$gostd"},
+			}},
+			{From: "example.com/lib", To: "os", Verdict: verdictAllowed, Lists: []*graphList{
+				{List: "Other", Allowed: true, Rule: "os"},
+			}},
+		},
+	}
+	act := buildGraph(testResults("repo"))
+	if diff := cmp.Diff(expected, act); diff != "" {
+		t.Errorf("Graph did not match expected\n%s", diff)
+	}
+}
+
+func TestGraphWriters(t *testing.T) {
+	scenarios := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "dot",
+			expected: `digraph depguard {
+	node [shape=box];
+	"example.com/app";
+	"fmt";
+	"os";
+	"reflect";
+	"strings";
+	"example.com/app" -> "fmt" [color=green, label="Main: $gostd, Other"];
+	"example.com/app" -> "os" [color=red, label="Other (deprecated)"];
+	"example.com/app" -> "reflect" [color=red, label="Tests: reflect"];
+	"example.com/app" -> "strings" [color=gray];
+}
+`,
+		},
+		{
+			format: "mermaid",
+			expected: `graph LR
+    n0["example.com/app"]
+    n1["fmt"]
+    n2["os"]
+    n3["reflect"]
+    n4["strings"]
+    n0 -->|"Main: $gostd, Other"| n1
+    n0 -->|"Other (deprecated)"| n2
+    n0 -->|"Tests: reflect"| n3
+    n0 --> n4
+    linkStyle 0 stroke:green
+    linkStyle 1 stroke:red
+    linkStyle 2 stroke:red
+    linkStyle 3 stroke:gray
+`,
+		},
+	}
+	g := &graph{
+		Nodes: []string{"example.com/app", "fmt", "os", "reflect", "strings"},
+		Edges: []*graphEdge{
+			{From: "example.com/app", To: "fmt", Verdict: verdictAllowed, Lists: []*graphList{
+				{List: "Main", Allowed: true, Rule: "$gostd"},
+				{List: "Other", Allowed: true},
+			}},
+			{From: "example.com/app", To: "os", Verdict: verdictDenied, Lists: []*graphList{
+				{List: "Other", Kind: "deprecated"},
+			}},
+			{From: "example.com/app", To: "reflect", Verdict: verdictDenied, Lists: []*graphList{
+				{List: "Main", Allowed: true, Rule: "$gostd"},
+				{List: "Tests", Allowed: false, Rule: "reflect"},
+			}},
+			{From: "example.com/app", To: "strings", Verdict: verdictUnchecked},
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := graphFormats[s.format].write(&buf, g); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(s.expected, buf.String()); diff != "" {
+				t.Errorf("Output did not match expected\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGraphWriterJSON(t *testing.T) {
+	var buf bytes.Buffer
+	g := &graph{
+		Nodes: []string{"a", "b"},
+		Edges: []*graphEdge{{From: "a", To: "b", Verdict: verdictDenied, Lists: []*graphList{{List: "Main", Rule: "b"}}}},
+	}
+	if err := graphFormats["json"].write(&buf, g); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := `{
+  "nodes": [
+    "a",
+    "b"
+  ],
+  "edges": [
+    {
+      "from": "a",
+      "to": "b",
+      "verdict": "denied",
+      "lists": [
+        {
+          "list": "Main",
+          "allowed": false,
+          "rule": "b"
+        }
+      ]
+    }
+  ]
+}
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("Output did not match expected\n%s", diff)
+	}
+}
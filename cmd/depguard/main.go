@@ -15,6 +15,7 @@ import (
 
 	"github.com/BurntSushi/toml"
 	depguard "github.com/OpenPeeDeeP/depguard/v2"
+	"golang.org/x/tools/go/analysis"
 	"golang.org/x/tools/go/analysis/singlechecker"
 	"gopkg.in/yaml.v3"
 )
@@ -28,22 +29,46 @@ var (
 		"yml":  &yamlConfigurator{},
 		"json": &jsonConfigurator{},
 	}
+	commands = map[string]func(args []string, stdout, stderr io.Writer) int{
+		"graph":    graphMain,
+		"coverage": coverageMain,
+		"cgo":      cgoMain,
+		"report":   reportMain,
+	}
 )
 
 func main() {
-	settings, err := getSettings()
-	if err != nil {
-		fmt.Printf("Could not find or read configuration file: %s\nUsing default configuration\n", err)
-		settings = &depguard.LinterSettings{}
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:], os.Stdout, os.Stderr))
+		}
 	}
-	analyzer, err := depguard.NewAnalyzer(settings)
+	analyzer, err := loadAnalyzer(os.Stdout)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	onlyNew(analyzer)
 	singlechecker.Main(analyzer)
 }
 
+// loadAnalyzer creates the analyzer from the configuration file, or the default
+// configuration when there is none, which it tells w about.
+func loadAnalyzer(w io.Writer) (*analysis.Analyzer, error) {
+	return depguard.NewAnalyzer(loadSettings(w))
+}
+
+// loadSettings reads the configuration file, or returns the default configuration
+// when there is none, which it tells w about.
+func loadSettings(w io.Writer) *depguard.LinterSettings {
+	settings, err := getSettings()
+	if err != nil {
+		fmt.Fprintf(w, "Could not find or read configuration file: %s\nUsing default configuration\n", err)
+		settings = &depguard.LinterSettings{}
+	}
+	return settings
+}
+
 type configurator interface {
 	parse(io.Reader) (*depguard.LinterSettings, error)
 }
@@ -82,14 +107,16 @@ func (*yamlConfigurator) parse(r io.Reader) (*depguard.LinterSettings, error) {
 }
 
 func getSettings() (*depguard.LinterSettings, error) {
-	fy, f, ft, err := findFile(".")
+	dir := "."
+	fy, f, ft, err := findFile(dir)
 	if errors.Is(err, fs.ErrNotExist) {
 		arg := []string{"list", "-f", "{{.Root -}}"}
 		out, cerr := exec.Command("go", arg...).Output() 
 		if cerr != nil {
 			return nil, cerr
 		}
-		fy, f, ft, err = findFile(strings.TrimRight(string(out), "\r\n"))
+		dir = strings.TrimRight(string(out), "\r\n")
+		fy, f, ft, err = findFile(dir)
 	}
 	// careful: be sure to overwrite err (not shadow!) in the nested scope above ;)
 	if err != nil {
@@ -103,7 +130,28 @@ func getSettings() (*depguard.LinterSettings, error) {
 		return nil, fmt.Errorf("could not open %s to read: %w", f, err)
 	}
 	defer file.Close()
-	return ft.parse(file)
+	set, err := ft.parse(file)
+	if err != nil {
+		return nil, err
+	}
+	resolveRoots(set, dir)
+	return set, nil
+}
+
+// resolveRoots makes relative list roots and vulnerability databases relative to the
+// directory of the configuration file instead of wherever depguard happens to be run from.
+func resolveRoots(set *depguard.LinterSettings, dir string) {
+	for _, l := range *set {
+		if l == nil {
+			continue
+		}
+		if l.Root != "" && !filepath.IsAbs(l.Root) {
+			l.Root = filepath.Join(dir, l.Root)
+		}
+		if l.VulnDB != "" && !filepath.IsAbs(l.VulnDB) {
+			l.VulnDB = filepath.Join(dir, l.VulnDB)
+		}
+	}
 }
 
 // The returned filepath is relative to given base path rel, or 
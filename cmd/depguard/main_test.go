@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"path/filepath"
 	"testing"
 
 	"github.com/OpenPeeDeeP/depguard/v2"
@@ -20,6 +21,14 @@ var expectedConfigStruct = &depguard.LinterSettings{
 			"reflect":                "Who needs reflection",
 			"github.com/OpenPeeDeeP": "Use Something Else",
 		},
+		Owner: "platform-team",
+		DenyMetadata: map[string]*depguard.Metadata{
+			"reflect": {
+				Owner:  "core-team",
+				URL:    "https://example.com/wiki/reflection",
+				Reason: "Reflection hides bugs from the compiler",
+			},
+		},
 	},
 	"tests": &depguard.List{
 		Files: []string{"$test"},
@@ -77,3 +86,24 @@ func TestTomlConfigurator(t *testing.T) {
 		t.Errorf("did not create expected config\n%s", diff)
 	}
 }
+
+func TestResolveRoots(t *testing.T) {
+	abs, err := filepath.Abs("/abs/root")
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &depguard.LinterSettings{
+		"relative": &depguard.List{Root: "sub", VulnDB: "vulns"},
+		"absolute": &depguard.List{Root: abs, VulnDB: abs},
+		"empty":    &depguard.List{},
+	}
+	resolveRoots(set, filepath.Join("config", "dir"))
+	exp := &depguard.LinterSettings{
+		"relative": &depguard.List{Root: filepath.Join("config", "dir", "sub"), VulnDB: filepath.Join("config", "dir", "vulns")},
+		"absolute": &depguard.List{Root: abs, VulnDB: abs},
+		"empty":    &depguard.List{},
+	}
+	if diff := cmp.Diff(exp, set); diff != "" {
+		t.Errorf("did not resolve roots as expected\n%s", diff)
+	}
+}
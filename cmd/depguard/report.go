@@ -0,0 +1,358 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// reportVersion is the version of the report schema, raised whenever a change to it
+// could break the tools reading it.
+const reportVersion = 1
+
+var (
+	reporters = map[string]reporter{
+		"json":       &jsonReporter{},
+		"checkstyle": &checkstyleReporter{},
+		"junit":      &junitReporter{},
+	}
+)
+
+// report is what depguard found in the packages it checked.
+type report struct {
+	Version int `json:"version"`
+	// Packages are the import paths of the packages that were checked.
+	Packages   []string     `json:"packages"`
+	Violations []*violation `json:"violations"`
+}
+
+// violation is something one of the lists did not allow.
+type violation struct {
+	Package string `json:"package"`
+	// File is relative to the working directory when it is within it.
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	// Import is empty for import budgets, which are about the package itself.
+	Import     string `json:"import,omitempty"`
+	Symbol     string `json:"symbol,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	List       string `json:"list"`
+	Mode       string `json:"mode"`
+	Rule       string `json:"rule,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Severity   string `json:"severity"`
+	Owner      string `json:"owner,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	Message    string `json:"message"`
+}
+
+// buildReport collects the violations of the packages people wrote.
+func buildReport(results []*packageResult, wd string) *report {
+	r := &report{Version: reportVersion, Packages: []string{}, Violations: []*violation{}}
+	for _, pr := range userPackages(results) {
+		path := pr.pkg.PkgPath
+		r.Packages = append(r.Packages, path)
+		if pr.result == nil {
+			continue
+		}
+		for _, v := range pr.result.Violations {
+			pos := pr.pkg.Fset.Position(v.Pos)
+			vi := violation{
+				Package:    path,
+				File:       relativeFile(wd, v.File),
+				Line:       pos.Line,
+				Column:     pos.Column,
+				Import:     v.Import,
+				Symbol:     v.Symbol,
+				Kind:       v.Kind,
+				List:       v.List,
+				Mode:       v.Mode,
+				Rule:       v.Rule,
+				Suggestion: v.Suggestion,
+				Severity:   v.Severity,
+				Owner:      v.Owner,
+				URL:        v.URL,
+				Reason:     v.Reason,
+				Message:    v.Message,
+			}
+			r.Violations = append(r.Violations, &vi)
+		}
+	}
+	sort.Strings(r.Packages)
+	sort.SliceStable(r.Violations, func(i, j int) bool {
+		a, b := r.Violations[i], r.Violations[j]
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return r
+}
+
+// relativeFile makes the file relative to the working directory wd when it is within it.
+func relativeFile(wd, file string) string {
+	if wd == "" {
+		return file
+	}
+	rel, err := filepath.Rel(wd, filepath.FromSlash(file))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return file
+	}
+	return filepath.ToSlash(rel)
+}
+
+// failed reports whether any of the violations is an error.
+func (r *report) failed() bool {
+	for _, v := range r.Violations {
+		if v.isError() {
+			return true
+		}
+	}
+	return false
+}
+
+// isError reports whether the violation fails the report, which only those with the
+// error severity do.
+func (v *violation) isError() bool {
+	return v.Severity == "error"
+}
+
+type reporter interface {
+	write(io.Writer, *report) error
+}
+
+type jsonReporter struct{}
+
+func (*jsonReporter) write(w io.Writer, r *report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type checkstyleReporter struct{}
+
+type checkstyleReport struct {
+	XMLName xml.Name          `xml:"checkstyle"`
+	Version string            `xml:"version,attr"`
+	Files   []*checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string             `xml:"name,attr"`
+	Errors []*checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+	Owner    string `xml:"owner,attr,omitempty"`
+	URL      string `xml:"url,attr,omitempty"`
+	Reason   string `xml:"reason,attr,omitempty"`
+}
+
+func (*checkstyleReporter) write(w io.Writer, r *report) error {
+	cs := &checkstyleReport{Version: "5.0"}
+	files := make(map[string]*checkstyleFile)
+	for _, v := range r.Violations {
+		f := files[v.File]
+		if f == nil {
+			f = &checkstyleFile{Name: v.File}
+			files[v.File] = f
+			cs.Files = append(cs.Files, f)
+		}
+		f.Errors = append(f.Errors, &checkstyleError{
+			Line:     v.Line,
+			Column:   v.Column,
+			Severity: v.Severity,
+			Message:  v.Message,
+			Source:   "depguard." + v.List,
+			Owner:    v.Owner,
+			URL:      v.URL,
+			Reason:   v.Reason,
+		})
+	}
+	return writeXML(w, cs)
+}
+
+// junitReporter reports every package as a testcase, which fails when any of its
+// violations is an error. The other violations are written as the output of the
+// testcase, so the failures agree with the exit code.
+type junitReporter struct{}
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func (*junitReporter) write(w io.Writer, r *report) error {
+	suite := &junitTestSuite{Name: "depguard", Tests: len(r.Packages)}
+	errs := make(map[string][]*violation, len(r.Packages))
+	others := make(map[string][]*violation, len(r.Packages))
+	for _, v := range r.Violations {
+		if v.isError() {
+			errs[v.Package] = append(errs[v.Package], v)
+		} else {
+			others[v.Package] = append(others[v.Package], v)
+		}
+	}
+	for _, pkg := range r.Packages {
+		tc := &junitTestCase{Name: pkg, ClassName: "depguard", SystemOut: joinDetails(others[pkg], true)}
+		if vs := errs[pkg]; len(vs) > 0 {
+			message := vs[0].Message
+			if len(vs) > 1 {
+				message = fmt.Sprintf("%d violations", len(vs))
+			}
+			tc.Failure = &junitFailure{Message: message, Type: "error", Text: joinDetails(vs, false)}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	return writeXML(w, &junitTestSuites{
+		Name:     "depguard",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Suites:   []*junitTestSuite{suite},
+	})
+}
+
+// details describes the violation in a few lines for the reports that have room for text.
+func (v *violation) details() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%d:%d: %s", v.File, v.Line, v.Column, v.Message)
+	for _, md := range []struct{ name, value string }{
+		{name: "Reason", value: v.Reason},
+		{name: "Owner", value: v.Owner},
+		{name: "URL", value: v.URL},
+	} {
+		if md.value != "" {
+			fmt.Fprintf(&b, "\n%s: %s", md.name, md.value)
+		}
+	}
+	return b.String()
+}
+
+// joinDetails describes the violations one after the other, with a blank line between
+// them, leading with their severity when they can have different ones.
+func joinDetails(vs []*violation, severity bool) string {
+	details := make([]string, 0, len(vs))
+	for _, v := range vs {
+		if severity {
+			details = append(details, v.Severity+": "+v.details())
+		} else {
+			details = append(details, v.details())
+		}
+	}
+	return strings.Join(details, "\n\n")
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// reportMain runs the report command with its arguments, returning the exit code.
+func reportMain(args []string, stdout, stderr io.Writer) int {
+	analyzer, err := loadAnalyzer(stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	onlyNew(analyzer)
+
+	fs := flag.NewFlagSet("depguard report", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("format", "json", "output `format` of the report: "+strings.Join(reporterNames(), ", "))
+	tests := fs.Bool("test", true, "include test packages")
+	analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: depguard report [-format name] [-test=false] [flags] [packages]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	rep, ok := reporters[*format]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown report format %q\n", *format)
+		return 2
+	}
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	results, err := analyze(analyzer, *tests, patterns)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	wd, _ := os.Getwd()
+	r := buildReport(results, wd)
+	if err := rep.write(stdout, r); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	if r.failed() {
+		// Matches the exit code of the analysis drivers when there are diagnostics
+		return 3
+	}
+	return 0
+}
+
+func reporterNames() []string {
+	names := make([]string, 0, len(reporters))
+	for name := range reporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBuildReport(t *testing.T) {
+	wd, err := filepath.Abs("repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &report{
+		Version:  reportVersion,
+		Packages: []string{"example.com/app", "example.com/lib"},
+		Violations: []*violation{
+			{
+				Package:  "example.com/app",
+				File:     "app/a.go",
+				Line:     1,
+				Column:   1,
+				Kind:     "imports",
+				List:     "Tests",
+				Severity: "warning",
+				Message:  "package 'example.com/app' imports 6 packages, more than the 5 list 'Tests' allows",
+			},
+			{
+				Package:  "example.com/app",
+				File:     "app/a.go",
+				Line:     2,
+				Column:   8,
+				Import:   "C",
+				Kind:     "cgo",
+				List:     "NoCgo",
+				Rule:     "$cgo",
+				Severity: "error",
+				Message:  "cgo import 'C' is not allowed from list 'NoCgo'",
+			},
+			{
+				Package:    "example.com/app",
+				File:       "app/a.go",
+				Line:       3,
+				Column:     3,
+				Import:     "reflect",
+				List:       "Main",
+				Mode:       "Strict",
+				Rule:       "reflect",
+				Suggestion: "Who needs reflection",
+				Severity:   "error",
+				Owner:      "core-team",
+				Message:    "import 'reflect' is not allowed from list 'Main': Who needs reflection",
+			},
+			{
+				Package:  "example.com/app",
+				File:     "app/a.go",
+				Line:     4,
+				Column:   3,
+				Import:   "os",
+				Kind:     "deprecated",
+				List:     "Other",
+				Severity: "warning",
+				Message:  "deprecated import 'os' is not allowed from list 'Other'",
+			},
+			{
+				Package:  "example.com/app",
+				File:     "app/a_test.go",
+				Line:     2,
+				Column:   6,
+				Import:   "github.com/stretchr/testify/assert",
+				List:     "Tests",
+				Mode:     "Original",
+				Rule:     "github.com/stretchr/testify",
+				Severity: "warning",
+				Message:  "import 'github.com/stretchr/testify/assert' is not allowed from list 'Tests'",
+			},
+		},
+	}
+	act := buildReport(testResults(wd), wd)
+	if diff := cmp.Diff(expected, act); diff != "" {
+		t.Errorf("Report did not match expected\n%s", diff)
+	}
+	if !act.failed() {
+		t.Error("Expected a report with an error to fail")
+	}
+	var warnings []*violation
+	for _, v := range act.Violations {
+		if v.Severity == "warning" {
+			warnings = append(warnings, v)
+		}
+	}
+	act.Violations = warnings
+	if act.failed() {
+		t.Error("Expected a report with only warnings not to fail")
+	}
+}
+
+func TestRelativeFile(t *testing.T) {
+	wd, err := filepath.Abs("repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{file: filepath.ToSlash(filepath.Join(wd, "pkg", "a.go")), expected: "pkg/a.go"},
+		{file: filepath.ToSlash(filepath.Join(filepath.Dir(wd), "other", "a.go")), expected: filepath.ToSlash(filepath.Join(filepath.Dir(wd), "other", "a.go"))},
+	}
+	for _, tc := range tests {
+		if act := relativeFile(wd, tc.file); act != tc.expected {
+			t.Errorf("Relative file didn't match expected: Exp %s: Act: %s", tc.expected, act)
+		}
+	}
+}
+
+func TestJSONReporter(t *testing.T) {
+	var buf bytes.Buffer
+	err := reporters["json"].write(&buf, &report{
+		Version:  reportVersion,
+		Packages: []string{"example.com/app"},
+		Violations: []*violation{{
+			Package:  "example.com/app",
+			File:     "a.go",
+			Line:     3,
+			Column:   2,
+			Kind:     "imports",
+			List:     "Main",
+			Mode:     "Original",
+			Severity: "info",
+			Owner:    "platform-team",
+			URL:      "https://example.com/wiki/budgets",
+			Reason:   "Keep packages small",
+			Message:  "package 'example.com/app' has 3 imports, more than the 2 allowed from list 'Main'",
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expected := `{
+  "version": 1,
+  "packages": [
+    "example.com/app"
+  ],
+  "violations": [
+    {
+      "package": "example.com/app",
+      "file": "a.go",
+      "line": 3,
+      "column": 2,
+      "kind": "imports",
+      "list": "Main",
+      "mode": "Original",
+      "severity": "info",
+      "owner": "platform-team",
+      "url": "https://example.com/wiki/budgets",
+      "reason": "Keep packages small",
+      "message": "package 'example.com/app' has 3 imports, more than the 2 allowed from list 'Main'"
+    }
+  ]
+}
+`
+	if diff := cmp.Diff(expected, buf.String()); diff != "" {
+		t.Errorf("Output did not match expected\n%s", diff)
+	}
+}
+
+func TestXMLReporters(t *testing.T) {
+	r := &report{
+		Version:  reportVersion,
+		Packages: []string{"example.com/app", "example.com/lib", "example.com/tool"},
+		Violations: []*violation{
+			{
+				Package:  "example.com/app",
+				File:     "app/a.go",
+				Line:     3,
+				Column:   2,
+				Import:   "reflect",
+				List:     "Main",
+				Severity: "error",
+				Owner:    "core-team",
+				Reason:   "Reflection hides bugs",
+				Message:  "import 'reflect' is not allowed from list 'Main'",
+			},
+			{
+				Package:  "example.com/app",
+				File:     "app/a_test.go",
+				Line:     5,
+				Column:   2,
+				Import:   "os",
+				List:     "Tests",
+				Severity: "warning",
+				Message:  "import 'os' is not allowed from list 'Tests': <use testing>",
+			},
+			{
+				Package:  "example.com/tool",
+				File:     "tool/t.go",
+				Line:     3,
+				Column:   2,
+				Import:   "plugin",
+				List:     "Main",
+				Severity: "error",
+				Message:  "import 'plugin' is not allowed from list 'Main'",
+			},
+			{
+				Package:  "example.com/tool",
+				File:     "tool/t.go",
+				Line:     4,
+				Column:   2,
+				Import:   "unsafe",
+				List:     "Main",
+				Severity: "error",
+				Message:  "import 'unsafe' is not allowed from list 'Main'",
+			},
+		},
+	}
+	scenarios := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "checkstyle",
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="5.0">
+  <file name="app/a.go">
+    <error line="3" column="2" severity="error" message="import &#39;reflect&#39; is not allowed from list &#39;Main&#39;" source="depguard.Main" owner="core-team" reason="Reflection hides bugs"></error>
+  </file>
+  <file name="app/a_test.go">
+    <error line="5" column="2" severity="warning" message="import &#39;os&#39; is not allowed from list &#39;Tests&#39;: &lt;use testing&gt;" source="depguard.Tests"></error>
+  </file>
+  <file name="tool/t.go">
+    <error line="3" column="2" severity="error" message="import &#39;plugin&#39; is not allowed from list &#39;Main&#39;" source="depguard.Main"></error>
+    <error line="4" column="2" severity="error" message="import &#39;unsafe&#39; is not allowed from list &#39;Main&#39;" source="depguard.Main"></error>
+  </file>
+</checkstyle>
+`,
+		},
+		{
+			format: "junit",
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="depguard" tests="3" failures="2">
+  <testsuite name="depguard" tests="3" failures="2">
+    <testcase name="example.com/app" classname="depguard">
+      <failure message="import &#39;reflect&#39; is not allowed from list &#39;Main&#39;" type="error">app/a.go:3:2: import &#39;reflect&#39; is not allowed from list &#39;Main&#39;&#xA;Reason: Reflection hides bugs&#xA;Owner: core-team</failure>
+      <system-out>warning: app/a_test.go:5:2: import &#39;os&#39; is not allowed from list &#39;Tests&#39;: &lt;use testing&gt;</system-out>
+    </testcase>
+    <testcase name="example.com/lib" classname="depguard"></testcase>
+    <testcase name="example.com/tool" classname="depguard">
+      <failure message="2 violations" type="error">tool/t.go:3:2: import &#39;plugin&#39; is not allowed from list &#39;Main&#39;&#xA;&#xA;tool/t.go:4:2: import &#39;unsafe&#39; is not allowed from list &#39;Main&#39;</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		},
+	}
+	for _, s := range scenarios {
+		t.Run(s.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := reporters[s.format].write(&buf, r); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if diff := cmp.Diff(s.expected, buf.String()); diff != "" {
+				t.Errorf("Output did not match expected\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package depguard
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"path"
+	"strings"
+)
+
+// Lists of the GOOS and GOARCH values that are understood in file names.
+// Kept in line with go/build's syslist.go.
+var (
+	knownOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+		"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+		"windows": true, "zos": true,
+	}
+	knownArch = map[string]bool{
+		"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+		"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+		"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+		"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+		"sparc": true, "sparc64": true, "wasm": true,
+	}
+	unixOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"hurd": true, "illumos": true, "ios": true, "linux": true, "netbsd": true,
+		"openbsd": true, "solaris": true,
+	}
+	// impliedOS are operating systems that also satisfy another one, like go/build does.
+	impliedOS = map[string]string{
+		"android": "linux",
+		"illumos": "solaris",
+		"ios":     "darwin",
+	}
+)
+
+// fileConstraint returns the build constraint of a file combining its //go:build
+// (or // +build) lines with the GOOS and GOARCH in its name.
+// It returns nil for files that are not constrained.
+func fileConstraint(file *ast.File, fileName string) constraint.Expr {
+	var expr, plusExpr constraint.Expr
+	for _, cg := range file.Comments {
+		if cg.Pos() > file.Package {
+			break
+		}
+		for _, c := range cg.List {
+			switch {
+			case constraint.IsGoBuild(c.Text):
+				if x, err := constraint.Parse(c.Text); err == nil {
+					expr = x
+				}
+			case constraint.IsPlusBuild(c.Text):
+				if x, err := constraint.Parse(c.Text); err == nil {
+					plusExpr = andExpr(plusExpr, x)
+				}
+			}
+		}
+	}
+	if expr == nil {
+		expr = plusExpr
+	}
+	return andExpr(expr, fileNameConstraint(fileName))
+}
+
+// fileNameConstraint returns the constraint implied by a name such as
+// foo_windows.go or foo_linux_amd64_test.go, nil if there is none.
+func fileNameConstraint(fileName string) constraint.Expr {
+	name := strings.TrimSuffix(path.Base(fileName), ".go")
+	name = strings.TrimSuffix(name, "_test")
+	i := strings.IndexByte(name, '_')
+	if i < 0 {
+		return nil
+	}
+	l := strings.Split(name[i:], "_")
+	n := len(l)
+	if n >= 2 && knownOS[l[n-2]] && knownArch[l[n-1]] {
+		return andExpr(&constraint.TagExpr{Tag: l[n-2]}, &constraint.TagExpr{Tag: l[n-1]})
+	}
+	if knownOS[l[n-1]] || knownArch[l[n-1]] {
+		return &constraint.TagExpr{Tag: l[n-1]}
+	}
+	return nil
+}
+
+func andExpr(x, y constraint.Expr) constraint.Expr {
+	switch {
+	case x == nil:
+		return y
+	case y == nil:
+		return x
+	default:
+		return &constraint.AndExpr{X: x, Y: y}
+	}
+}
+
+// constrainedTo reports whether a file with the constraint only builds because
+// of the tags, meaning it is satisfied with them and not satisfied without them.
+// Go version tags are always considered satisfied.
+func constrainedTo(expr constraint.Expr, tags []string) bool {
+	if expr == nil {
+		return false
+	}
+	set := make(map[string]bool, len(tags))
+	for _, t := range tags {
+		set[t] = true
+		if unixOS[t] {
+			set["unix"] = true
+		}
+		if os, ok := impliedOS[t]; ok {
+			set[os] = true
+		}
+	}
+	with := expr.Eval(func(tag string) bool {
+		return set[tag] || strings.HasPrefix(tag, "go1.")
+	})
+	without := expr.Eval(func(tag string) bool {
+		return strings.HasPrefix(tag, "go1.")
+	})
+	return with && !without
+}
@@ -0,0 +1,73 @@
+package depguard
+
+import (
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+func TestFileConstraint(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		src      string
+		expected string
+	}{
+		{name: "none", fileName: "foo.go", src: "package foo"},
+		{name: "go build", fileName: "foo.go", src: "//go:build linux || darwin\n\npackage foo", expected: "linux || darwin"},
+		{name: "plus build", fileName: "foo.go", src: "// +build linux darwin\n// +build amd64\n\npackage foo", expected: "(linux || darwin) && amd64"},
+		{name: "go build wins", fileName: "foo.go", src: "//go:build windows\n// +build linux\n\npackage foo", expected: "windows"},
+		{name: "after package clause", fileName: "foo.go", src: "package foo\n\n//go:build linux"},
+		{name: "os file name", fileName: "/some/foo_windows.go", src: "package foo", expected: "windows"},
+		{name: "arch file name", fileName: "/some/foo_amd64_test.go", src: "package foo", expected: "amd64"},
+		{name: "os and arch file name", fileName: "/some/foo_linux_arm64.go", src: "package foo", expected: "linux && arm64"},
+		{name: "only os as file name", fileName: "/some/linux.go", src: "package foo"},
+		{name: "file name and go build", fileName: "/some/foo_linux.go", src: "//go:build cgo\n\npackage foo", expected: "cgo && linux"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), tc.fileName, tc.src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var act string
+			if expr := fileConstraint(f, tc.fileName); expr != nil {
+				act = expr.String()
+			}
+			if act != tc.expected {
+				t.Errorf("constraint did not match expected: Exp %s: Act: %s", tc.expected, act)
+			}
+		})
+	}
+}
+
+func TestConstrainedTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		tags     []string
+		expected bool
+	}{
+		{name: "unconstrained", src: "package foo", tags: []string{"linux"}},
+		{name: "matching os", src: "//go:build windows\n\npackage foo", tags: []string{"windows"}, expected: true},
+		{name: "other os", src: "//go:build windows\n\npackage foo", tags: []string{"linux"}},
+		{name: "one of many", src: "//go:build linux || darwin\n\npackage foo", tags: []string{"darwin"}, expected: true},
+		{name: "negated os", src: "//go:build !windows\n\npackage foo", tags: []string{"linux"}},
+		{name: "needs all tags", src: "//go:build linux && amd64\n\npackage foo", tags: []string{"linux"}},
+		{name: "has all tags", src: "//go:build linux && amd64\n\npackage foo", tags: []string{"linux", "amd64"}, expected: true},
+		{name: "unix", src: "//go:build unix\n\npackage foo", tags: []string{"freebsd"}, expected: true},
+		{name: "implied os", src: "//go:build linux\n\npackage foo", tags: []string{"android"}, expected: true},
+		{name: "go version only", src: "//go:build go1.21\n\npackage foo", tags: []string{"linux"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile(token.NewFileSet(), "foo.go", tc.src, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act := constrainedTo(fileConstraint(f, "foo.go"), tc.tags); act != tc.expected {
+				t.Errorf("constrained to %v: Exp %t: Act: %t", tc.tags, tc.expected, act)
+			}
+		})
+	}
+}
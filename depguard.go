@@ -1,11 +1,17 @@
 package depguard
 
 import (
-	"fmt"
+	"flag"
 	"go/ast"
+	"go/token"
+	"go/types"
 	"path/filepath"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
 
+	"golang.org/x/mod/modfile"
 	"golang.org/x/tools/go/analysis"
 )
 
@@ -18,76 +24,784 @@ func NewAnalyzer(settings *LinterSettings) (*analysis.Analyzer, error) {
 		return nil, err
 	}
 	analyzer := newAnalyzer(s.run)
+	s.setFactTypes(analyzer)
 	return analyzer, nil
 }
 
 type UncompiledAnalyzer struct {
 	Analyzer *analysis.Analyzer
 	settings *LinterSettings
+
+	// The settings are compiled once, as compiling expands them in place
+	once     sync.Once
+	compiled linterSettings
+	err      error
 }
 
 // NewUncompiledAnalyzer creates a new analyzer from the settings passed in.
 // This can never error unlike NewAnalyzer.
 // It is advised to call the Compile method on the returned Analyzer before running.
 func NewUncompiledAnalyzer(settings *LinterSettings) *UncompiledAnalyzer {
-	return &UncompiledAnalyzer{
-		Analyzer: newAnalyzer(settings.run),
-		settings: settings,
-	}
+	ua := &UncompiledAnalyzer{settings: settings}
+	ua.Analyzer = newAnalyzer(ua.run)
+	settings.setFactTypes(ua.Analyzer)
+	return ua
 }
 
 // Compile the settings ahead of time so each subsuquent run of the analyzer doesn't
 // need to do this work.
 func (ua *UncompiledAnalyzer) Compile() error {
-	s, err := ua.settings.compile()
+	s, err := ua.compile()
 	if err != nil {
 		return err
 	}
-	ua.Analyzer.Run = s.run
+	s.setFactTypes(ua.Analyzer)
 	return nil
 }
 
-func (settings LinterSettings) run(pass *analysis.Pass) (interface{}, error) {
-	s, err := settings.compile()
+// compile compiles the settings the first time it is called, returning the same
+// compiled settings or error every time after.
+func (ua *UncompiledAnalyzer) compile() (linterSettings, error) {
+	ua.once.Do(func() {
+		ua.compiled, ua.err = ua.settings.compile()
+	})
+	return ua.compiled, ua.err
+}
+
+func (ua *UncompiledAnalyzer) run(pass *analysis.Pass) (interface{}, error) {
+	s, err := ua.compile()
 	if err != nil {
 		return nil, err
 	}
 	return s.run(pass)
 }
 
+// skipGeneratedFlag is the name of the analyzer flag to not check generated files at all.
+const skipGeneratedFlag = "skip-generated"
+
 func newAnalyzer(run func(*analysis.Pass) (interface{}, error)) *analysis.Analyzer {
-	return &analysis.Analyzer{
+	a := &analysis.Analyzer{
 		Name:             "depguard",
 		Doc:              "Go linter that checks if package imports are in a list of acceptable packages",
 		URL:              "https://github.com/OpenPeeDeeP/depguard",
 		Run:              run,
 		RunDespiteErrors: false,
+		ResultType:       reflect.TypeOf((*Result)(nil)),
 	}
+	a.Flags.Bool(skipGeneratedFlag, false, "do not check generated files")
+	return a
+}
+
+// boolFlag returns the value of one of the analyzer's boolean flags.
+func boolFlag(pass *analysis.Pass, name string) bool {
+	f := pass.Analyzer.Flags.Lookup(name)
+	if f == nil {
+		return false
+	}
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return false
+	}
+	b, _ := getter.Get().(bool)
+	return b
 }
 
 func (s linterSettings) run(pass *analysis.Pass) (interface{}, error) {
+	var graph *moduleGraphFact
+	var modules map[string]string
+	if s.usesModuleGraph() {
+		if !hasFactType(pass, new(moduleGraphFact)) {
+			return nil, errMissingModuleGraph
+		}
+		graph, modules = moduleGraph(pass, packageModuleRoot(pass))
+	}
+	skipGenerated := boolFlag(pass, skipGeneratedFlag)
+	imported := make(map[string]*types.Package, len(pass.Pkg.Imports()))
+	for _, pkg := range pass.Pkg.Imports() {
+		imported[pkg.Path()] = pkg
+	}
+	budgets := make(map[*list]*importBudget)
+	res := &Result{}
+	cp := &checkPass{Pass: pass, res: res}
 	for _, file := range pass.Files {
 		// For Windows need to replace separator with '/'
 		fileName := filepath.ToSlash(pass.Fset.Position(file.Pos()).Filename)
-		lists := s.whichLists(fileName)
+		cgo := isCgoGenerated(file)
+		if cgo && fileName == filepath.ToSlash(pass.Fset.File(file.Pos()).Name()) {
+			// Support files cgo generates from scratch don't map back to any source file
+			continue
+		}
+		// Files rewritten by cgo are still the code that was written by hand
+		generated := ast.IsGenerated(file) && !cgo
+		if generated && skipGenerated {
+			continue
+		}
+		moduleRoot := findModuleRoot(filepath.Dir(filepath.FromSlash(fileName)))
+		src := &source{
+			fileName:   fileName,
+			pkgPath:    pass.Pkg.Path(),
+			moduleRoot: filepath.ToSlash(moduleRoot),
+			modulePath: findModulePath(moduleRoot),
+			constraint: fileConstraint(file, fileName),
+			generated:  generated,
+		}
+		lists := s.whichLists(src)
+		versions := requiredVersions(moduleRoot)
 		for _, imp := range file.Imports {
+			path, name := importOf(imp, cgo)
+			if path == "C" {
+				res.CgoFiles = append(res.CgoFiles, fileName)
+			}
+			version := importVersion(path, versions)
 			for _, l := range lists {
-				if allowed, sugg := l.importAllowed(rawBasicLit(imp.Path)); !allowed {
-					diag := analysis.Diagnostic{
-						Pos:     imp.Pos(),
-						End:     imp.End(),
-						Message: fmt.Sprintf("import '%s' is not allowed from list '%s'", rawBasicLit(imp.Path), l.name),
-					}
-					if sugg != "" {
-						diag.Message = fmt.Sprintf("%s: %s", diag.Message, sugg)
-						diag.SuggestedFixes = append(diag.SuggestedFixes, analysis.SuggestedFix{Message: sugg})
-					}
-					pass.Report(diag)
+				v := l.importAllowed(path, version)
+				res.Imports = append(res.Imports, &ImportResult{
+					Pos:        imp.Pos(),
+					File:       fileName,
+					Importer:   pass.Pkg.Path(),
+					Import:     path,
+					List:       l.name,
+					Allowed:    v.allowed,
+					Rule:       v.rule,
+					AllowEntry: v.allowEntry,
+					DenyEntry:  v.denyEntry,
+				})
+				if v.allowed {
+					continue
+				}
+				if path == "C" {
+					v.kind = "cgo"
+				}
+				err := l.report(cp, imp, v, &messageData{
+					Import:  path,
+					File:    fileName,
+					Version: version,
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+			if err := checkReplaced(cp, imp, path, src, lists, versions); err != nil {
+				return nil, err
+			}
+			if err := checkVendored(cp, imp, path, src, lists); err != nil {
+				return nil, err
+			}
+			if err := checkTestOnly(cp, imp, path, fileName, lists); err != nil {
+				return nil, err
+			}
+			if err := checkDirs(cp, imp, imported[path], fileName, lists); err != nil {
+				return nil, err
+			}
+			if err := checkVisibility(cp, imp, path, src, lists); err != nil {
+				return nil, err
+			}
+			if err := checkStyle(cp, imp, path, name, fileName, lists, imported[path]); err != nil {
+				return nil, err
+			}
+			if err := checkLicenses(cp, imp, path, src, lists, imported[path], versions); err != nil {
+				return nil, err
+			}
+			if err := checkDeprecated(cp, imp, path, src, lists, imported[path], versions); err != nil {
+				return nil, err
+			}
+			if err := checkRetracted(cp, imp, path, src, lists, versions); err != nil {
+				return nil, err
+			}
+			if err := checkVulns(cp, imp, path, src, lists, versions); err != nil {
+				return nil, err
+			}
+			if err := checkModules(cp, imp, path, fileName, lists, graph, modules); err != nil {
+				return nil, err
+			}
+		}
+		if err := checkSymbols(cp, file, fileName, lists); err != nil {
+			return nil, err
+		}
+		countBudgets(pass, file, src, lists, imported, budgets)
+	}
+	if err := checkBudgets(cp, s, budgets); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// importBudget collects what a package imports across the files a list with
+// an import limit matched.
+type importBudget struct {
+	// file is the first file of the package the list matched, reported at its package clause.
+	file     *ast.File
+	fileName string
+	imports  map[string]struct{}
+	modules  map[string]struct{}
+	// positions are where the counted imports are.
+	positions []token.Pos
+}
+
+// countBudgets adds the imports of a file outside of the standard library and
+// the package's own module to the budgets of the lists that have a limit.
+func countBudgets(pass *analysis.Pass, file *ast.File, src *source, lists []*list, imported map[string]*types.Package, budgets map[*list]*importBudget) {
+	var requires []string
+	var loaded bool
+	for _, l := range lists {
+		if l.maxImports <= 0 && l.maxModules <= 0 {
+			continue
+		}
+		if !loaded {
+			requires = requiredModules(filepath.FromSlash(src.moduleRoot))
+			loaded = true
+		}
+		b := budgets[l]
+		if b == nil {
+			b = &importBudget{
+				file:     file,
+				fileName: src.fileName,
+				imports:  make(map[string]struct{}),
+				modules:  make(map[string]struct{}),
+			}
+			budgets[l] = b
+		}
+		for _, imp := range file.Imports {
+			path, _ := importOf(imp, false)
+			if isStdLib(path) || inModule(path, src.modulePath) {
+				continue
+			}
+			b.imports[path] = struct{}{}
+			b.modules[importModule(pass.Fset, imported[path], path, requires)] = struct{}{}
+			b.positions = append(b.positions, imp.Pos())
+		}
+	}
+}
+
+// checkBudgets reports a package that has more imports or external modules than one of the lists allows.
+func checkBudgets(pass *checkPass, s linterSettings, budgets map[*list]*importBudget) error {
+	for _, l := range s {
+		b := budgets[l]
+		if b == nil {
+			continue
+		}
+		for _, limit := range []struct {
+			kind  string
+			items map[string]struct{}
+			max   int
+		}{
+			{kind: "imports", items: b.imports, max: l.maxImports},
+			{kind: "external modules", items: b.modules, max: l.maxModules},
+		} {
+			items := make([]string, 0, len(limit.items))
+			for item := range limit.items {
+				items = append(items, item)
+			}
+			sort.Strings(items)
+			v := l.budgetAllowed(items, limit.max)
+			if v.allowed {
+				continue
+			}
+			v.kind = limit.kind
+			v.related = b.positions
+			err := l.report(pass, b.file.Name, v, &messageData{
+				Package: pass.Pkg.Path(),
+				File:    b.fileName,
+				Count:   len(items),
+				Limit:   limit.max,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkStyle reports an import if the way it is named is not allowed by one of the lists.
+func checkStyle(pass *checkPass, imp *ast.ImportSpec, path, name, fileName string, lists []*list, pkg *types.Package) error {
+	var pkgName string
+	if pkg != nil {
+		pkgName = pkg.Name()
+	}
+	for _, l := range lists {
+		v := l.styleAllowed(path, name, pkgName)
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import: path,
+			Alias:  name,
+			File:   fileName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkReplaced reports an import of a module go.mod replaces in a way one of the lists
+// does not allow, including when the package it is replaced with is not allowed.
+func checkReplaced(pass *checkPass, imp *ast.ImportSpec, path string, src *source, lists []*list, versions map[string]string) error {
+	mod, version := requiredModule(path, versions)
+	if mod == "" {
+		return nil
+	}
+	var rep *modfile.Replace
+	var repPath string
+	var known bool
+	for _, l := range lists {
+		if !l.usesReplacements() {
+			continue
+		}
+		if !known {
+			// Only look the replacement up once a list needs it
+			rep = replacement(filepath.FromSlash(src.moduleRoot), mod, version)
+			repPath, known = replacedPath(path, mod, rep), true
+		}
+		if repPath != "" {
+			v := l.importAllowed(repPath, rep.New.Version)
+			if !v.allowed {
+				v.kind = "replaced"
+				if v.suggestion == "" {
+					v.suggestion = "it is replaced with " + repPath
+				}
+				err := l.report(pass, imp, v, &messageData{
+					Import:      path,
+					File:        src.fileName,
+					Version:     rep.New.Version,
+					Replacement: repPath,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+		v := l.replaceAllowed(mod, rep)
+		if v.allowed {
+			continue
+		}
+		data := &messageData{
+			Import:  path,
+			File:    src.fileName,
+			Version: version,
+		}
+		if rep != nil {
+			data.Replacement = rep.New.Path
+		}
+		if err := l.report(pass, imp, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVendored reports an import of a package that may only be used vendored when it isn't.
+func checkVendored(pass *checkPass, imp *ast.ImportSpec, path string, src *source, lists []*list) error {
+	if isStdLib(path) || inModule(path, src.modulePath) {
+		return nil
+	}
+	var vendored map[string]bool
+	var known bool
+	for _, l := range lists {
+		if len(l.vendoredOnly) == 0 {
+			continue
+		}
+		if !known {
+			vendored, known = vendoredPackages(filepath.FromSlash(src.moduleRoot)), true
+		}
+		v := l.vendoredAllowed(path, vendored[path])
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import: path,
+			File:   src.fileName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTestOnly reports an import of a test only package from a file that isn't a test.
+func checkTestOnly(pass *checkPass, imp *ast.ImportSpec, path, fileName string, lists []*list) error {
+	for _, l := range lists {
+		v := l.testOnlyAllowed(path, pass.Pkg.Path(), fileName)
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import: path,
+			File:   fileName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDirs reports an import of a package located in a directory one of the lists denies.
+func checkDirs(pass *checkPass, imp *ast.ImportSpec, pkg *types.Package, fileName string, lists []*list) error {
+	if pkg == nil {
+		return nil
+	}
+	var dir string
+	var known bool
+	for _, l := range lists {
+		if len(l.denyDirs) == 0 {
+			continue
+		}
+		if !known {
+			// Only look the directory up once a list needs it
+			if dir, known = moduleDir(pass.Fset, pkg); !known {
+				return nil
+			}
+		}
+		v := l.dirAllowed(dir)
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import: pkg.Path(),
+			File:   fileName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVisibility reports an import of a package that is not visible to the importing package.
+func checkVisibility(pass *checkPass, imp *ast.ImportSpec, path string, src *source, lists []*list) error {
+	var names, importerNames []string
+	for _, l := range lists {
+		if len(l.visibility) == 0 {
+			continue
+		}
+		if names == nil {
+			names = packageNames(path, src.modulePath)
+			importerNames = packageNames(importerPath(pass.Pkg), src.modulePath)
+		}
+		v := l.visibilityAllowed(names, importerNames)
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import: path,
+			File:   src.fileName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importerPath is the path of the package being checked, where an external test
+// package counts as the package it tests.
+func importerPath(pkg *types.Package) string {
+	if strings.HasSuffix(pkg.Name(), "_test") {
+		return strings.TrimSuffix(pkg.Path(), "_test")
+	}
+	return pkg.Path()
+}
+
+// checkLicenses reports an import of a module whose license is not allowed by one of the lists.
+func checkLicenses(pass *checkPass, imp *ast.ImportSpec, path string, src *source, lists []*list, pkg *types.Package, versions map[string]string) error {
+	// Files outside of a module, like the test main go generates, can't tell their own packages apart
+	if src.modulePath == "" || isStdLib(path) || inModule(path, src.modulePath) {
+		return nil
+	}
+	var license string
+	var known bool
+	for _, l := range lists {
+		if !l.usesLicenses() {
+			continue
+		}
+		if !known {
+			// Only look the license up once a list needs it
+			dir := moduleSourceDir(pass.Fset, pkg, path, filepath.FromSlash(src.moduleRoot), versions)
+			if dir == "" {
+				return nil
+			}
+			license, known = findLicense(dir), true
+		}
+		v := l.licenseAllowed(license)
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import:  path,
+			File:    src.fileName,
+			License: license,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDeprecated reports an import of a package or module that its authors deprecated.
+func checkDeprecated(pass *checkPass, imp *ast.ImportSpec, path string, src *source, lists []*list, pkg *types.Package, versions map[string]string) error {
+	if pkg == nil || isStdLib(path) {
+		return nil
+	}
+	var pkgMsg, modMsg string
+	var known bool
+	for _, l := range lists {
+		if !l.denyDeprecated {
+			continue
+		}
+		if !known {
+			// Only look the deprecations up once a list needs them
+			if dir := packageDir(pass.Fset, pkg); dir != "" {
+				pkgMsg = packageDeprecation(dir)
+			}
+			if src.modulePath != "" && !inModule(path, src.modulePath) {
+				// Deprecations are published in later versions, so like the go command read
+				// them from the latest one, or else from the go.mod where the module is found
+				if msg, ok := latestModuleDeprecation(path, versions); ok {
+					modMsg = msg
+				} else if dir := moduleSourceDir(pass.Fset, pkg, path, filepath.FromSlash(src.moduleRoot), versions); dir != "" {
+					modMsg = moduleDeprecation(dir)
 				}
 			}
+			known = true
+		}
+		v := l.deprecatedAllowed(pkgMsg, modMsg)
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import: path,
+			File:   src.fileName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRetracted reports an import of a module whose required version, or the version
+// of the module it is replaced with, has been retracted.
+func checkRetracted(pass *checkPass, imp *ast.ImportSpec, path string, src *source, lists []*list, versions map[string]string) error {
+	var mod, version string
+	var known bool
+	for _, l := range lists {
+		if !l.denyRetracted {
+			continue
+		}
+		if !known {
+			// Only resolve the import once a list needs it
+			_, mod, version = resolvedImport(filepath.FromSlash(src.moduleRoot), path, versions)
+			if mod == "" {
+				return nil
+			}
+			known = true
+		}
+		v := l.retractedAllowed(retractions(mod), version)
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import:  path,
+			File:    src.fileName,
+			Version: version,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkVulns reports an import of a package with known vulnerabilities at the
+// version required, according to the vulnerability database of one of the lists.
+// Standard library packages are checked at the version of Go the module builds with.
+func checkVulns(pass *checkPass, imp *ast.ImportSpec, path string, src *source, lists []*list, versions map[string]string) error {
+	var resPath, mod, version string
+	var known bool
+	for _, l := range lists {
+		if l.vulnDB == "" {
+			continue
+		}
+		if !known {
+			// Only resolve the import once a list needs it
+			root := filepath.FromSlash(src.moduleRoot)
+			resPath, mod, version = resolvedImport(root, path, versions)
+			if mod == "" && isStdLib(path) {
+				resPath, mod, version = path, stdlibModule, stdlibVersion(root)
+			}
+			if mod == "" || version == "" {
+				return nil
+			}
+			known = true
+		}
+		db, err := loadVulnDB(l.vulnDB)
+		if err != nil {
+			return err
+		}
+		v := l.vulnAllowed(db.affecting(resPath, mod, version), mod, version)
+		if v.allowed {
+			continue
+		}
+		err = l.report(pass, imp, v, &messageData{
+			Import:  path,
+			File:    src.fileName,
+			Version: version,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkModules reports an import that goes against the module order or creates a module cycle.
+func checkModules(pass *checkPass, imp *ast.ImportSpec, path, fileName string, lists []*list, graph *moduleGraphFact, modules map[string]string) error {
+	if graph == nil {
+		return nil
+	}
+	for _, l := range lists {
+		v := l.moduleAllowed(graph, graph.Module, modules[path])
+		if v.allowed {
+			continue
+		}
+		err := l.report(pass, imp, v, &messageData{
+			Import: path,
+			File:   fileName,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkSymbols reports every use of a package level symbol that is denied by one of the lists.
+func checkSymbols(pass *checkPass, file *ast.File, fileName string, lists []*list) error {
+	var symLists []*list
+	for _, l := range lists {
+		if len(l.denySymbols) > 0 {
+			symLists = append(symLists, l)
+		}
+	}
+	if len(symLists) == 0 {
+		return nil
+	}
+	var err error
+	ast.Inspect(file, func(n ast.Node) bool {
+		id, ok := n.(*ast.Ident)
+		if !ok || err != nil {
+			return err == nil
+		}
+		obj := pass.TypesInfo.Uses[id]
+		if obj == nil || obj.Pkg() == nil || obj.Pkg() == pass.Pkg || obj.Parent() != obj.Pkg().Scope() {
+			return true
+		}
+		sym := obj.Pkg().Path() + "." + obj.Name()
+		for _, l := range symLists {
+			v := l.symbolAllowed(sym)
+			if v.allowed {
+				continue
+			}
+			err = l.report(pass, id, v, &messageData{
+				Import: obj.Pkg().Path(),
+				Symbol: sym,
+				File:   fileName,
+			})
+			if err != nil {
+				return false
+			}
 		}
+		return true
+	})
+	return err
+}
+
+// report fills in what the list knows about the verdict and reports it at the node.
+func (l *list) report(pass *checkPass, node ast.Node, v *importVerdict, data *messageData) error {
+	data.List = l.name
+	data.Mode = l.listMode.String()
+	data.Suggestion = v.suggestion
+	data.MatchedRule = v.rule
+	data.Owner = v.metadata.Owner
+	data.URL = v.metadata.URL
+	data.Reason = v.metadata.Reason
+	data.Kind = v.kind
+	msg, err := l.formatMessage(data)
+	if err != nil {
+		return err
+	}
+	diag := analysis.Diagnostic{
+		Pos:     node.Pos(),
+		End:     node.End(),
+		Message: msg,
+		URL:     v.metadata.URL,
+	}
+	if v.suggestion != "" {
+		diag.SuggestedFixes = append(diag.SuggestedFixes, analysis.SuggestedFix{Message: v.suggestion})
+	}
+	for _, pos := range v.related {
+		diag.Related = append(diag.Related, analysis.RelatedInformation{Pos: pos, Message: "import counted against the limit"})
+	}
+	pass.Report(diag)
+	pass.res.Violations = append(pass.res.Violations, &Violation{
+		Pos:        node.Pos(),
+		File:       data.File,
+		Import:     data.Import,
+		Symbol:     data.Symbol,
+		Kind:       data.Kind,
+		List:       data.List,
+		Mode:       data.Mode,
+		Rule:       data.MatchedRule,
+		Suggestion: data.Suggestion,
+		Severity:   l.severityOrDefault(),
+		Owner:      data.Owner,
+		URL:        data.URL,
+		Reason:     data.Reason,
+		Message:    msg,
+		Related:    v.related,
+	})
+	return nil
+}
+
+// severityOrDefault is the severity of the list, error when it isn't configured.
+func (l *list) severityOrDefault() string {
+	if l.severity == "" {
+		return severityError
+	}
+	return l.severity
+}
+
+// checkPass is the pass being checked, which also records what is reported in the
+// result of the analyzer.
+type checkPass struct {
+	*analysis.Pass
+	res *Result
+}
+
+// cgoHeader starts every file that has been rewritten by cgo.
+const cgoHeader = "// Code generated by cmd/cgo"
+
+func isCgoGenerated(file *ast.File) bool {
+	return len(file.Comments) > 0 && strings.HasPrefix(file.Comments[0].List[0].Text, cgoHeader)
+}
+
+// importOf returns the package path and name of an import.
+// cgo rewrites `import "C"` into `import _ "unsafe"`, so in files it generated
+// that import is reported as the "C" pseudo package it started out as.
+func importOf(imp *ast.ImportSpec, cgo bool) (path, name string) {
+	path = rawBasicLit(imp.Path)
+	if imp.Name != nil {
+		name = imp.Name.Name
+	}
+	if cgo && path == "unsafe" && name == "_" {
+		return "C", ""
 	}
-	return nil, nil
+	return path, name
 }
 
 func rawBasicLit(lit *ast.BasicLit) string {
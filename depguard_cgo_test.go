@@ -0,0 +1,41 @@
+//go:build cgo
+
+package depguard
+
+import (
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzerCgo(t *testing.T) {
+	a, err := NewAnalyzer(&LinterSettings{"NoCgo": &List{
+		Files: []string{"$all", "!**/native/**"},
+		Deny: map[string]string{
+			"$cgo":   "keep builds static",
+			"unsafe": "",
+		},
+	}})
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	results := analysistest.Run(t, filepath.Join(analysistest.TestData(), "cgo"), a, "./...")
+	var found bool
+	for _, r := range results {
+		for _, d := range r.Diagnostics {
+			pos := r.Pass.Fset.Position(d.Pos)
+			if filepath.Base(pos.Filename) != "app.go" || pos.Line != 4 {
+				continue
+			}
+			found = true
+			// The import of "C" cgo rewrote must still point at the path as written
+			if pos.Column != 8 {
+				t.Errorf("expected the cgo import at column 8, got %s", pos)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected the cgo import to be reported")
+	}
+}
@@ -0,0 +1,113 @@
+package depguard
+
+import (
+	"errors"
+	"go/types"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestImporterPath(t *testing.T) {
+	tests := []struct {
+		path string
+		name string
+		exp  string
+	}{
+		{path: "example.com/mono/pkg/billing", name: "billing", exp: "example.com/mono/pkg/billing"},
+		{path: "example.com/mono/pkg/billing_test", name: "billing_test", exp: "example.com/mono/pkg/billing"},
+		{path: "example.com/mono/pkg/load_test", name: "load", exp: "example.com/mono/pkg/load_test"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			if act := importerPath(types.NewPackage(tc.path, tc.name)); act != tc.exp {
+				t.Errorf("Importer path didn't match expected: Exp %s: Act: %s", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestModuleGraphFactTypes(t *testing.T) {
+	settings := &LinterSettings{"Main": &List{ModuleOrder: []string{"example.com/core/**", "example.com/app/**"}}}
+	ua := NewUncompiledAnalyzer(settings)
+	if len(ua.Analyzer.FactTypes) != 1 {
+		t.Errorf("expected the uncompiled analyzer to declare the module graph fact: %v", ua.Analyzer.FactTypes)
+	}
+	if err := ua.Compile(); err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	if len(ua.Analyzer.FactTypes) != 1 {
+		t.Errorf("expected the compiled analyzer to declare the module graph fact: %v", ua.Analyzer.FactTypes)
+	}
+	if ua := NewUncompiledAnalyzer(&LinterSettings{"Main": &List{Deny: map[string]string{"reflect": ""}}}); len(ua.Analyzer.FactTypes) != 0 {
+		t.Errorf("expected no facts without module rules: %v", ua.Analyzer.FactTypes)
+	}
+	pass := &analysis.Pass{Analyzer: &analysis.Analyzer{Name: "depguard"}}
+	if _, err := ua.Analyzer.Run(pass); !errors.Is(err, errMissingModuleGraph) {
+		t.Errorf("expected running without the module graph fact to fail: %v", err)
+	}
+}
+
+func TestUncompiledAnalyzerCompilesOnce(t *testing.T) {
+	ua := NewUncompiledAnalyzer(&LinterSettings{"Main": &List{Allow: []string{"$gostd"}}})
+	for i := 0; i < 2; i++ {
+		s, err := ua.compile()
+		if err != nil {
+			t.Fatal("not expecting an error", err)
+		}
+		// Compiling the expanded settings again would lose which entries were variables
+		if v := s[0].importAllowed("FIND ME TOO", ""); !v.allowed || v.allowEntry != "$gostd" {
+			t.Errorf("Compile %d: expected FIND ME TOO to be allowed by $gostd: %+v", i+1, v)
+		}
+	}
+}
+
+func TestAnalyzerTestOnly(t *testing.T) {
+	a, err := NewAnalyzer(&LinterSettings{"Main": &List{DenyTestOnly: true}})
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	analysistest.Run(t, filepath.Join(analysistest.TestData(), "testonly"), a, "./...")
+}
+
+func TestAnalyzerSymbols(t *testing.T) {
+	a, err := NewAnalyzer(&LinterSettings{"Main": &List{
+		DenySymbols: map[string]string{
+			// Exact, so SplitN is still allowed
+			"strings.Split$": "use strings.SplitN",
+			// A prefix, so every Trim function is denied
+			"strings.Trim":     "",
+			"strings.Replace$": "use a strings.Replacer",
+			"os.Exit$":         "return an error instead",
+		},
+	}})
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	analysistest.Run(t, filepath.Join(analysistest.TestData(), "symbols"), a, "./...")
+}
+
+func TestAnalyzerBudgets(t *testing.T) {
+	dir := filepath.Join(analysistest.TestData(), "budgets")
+	settings := func() *LinterSettings {
+		return &LinterSettings{"Main": &List{MaxImports: 3, MaxExternalModules: 2}}
+	}
+	a, err := NewAnalyzer(settings())
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	// The import of the generated file puts both budgets over their limit
+	analysistest.Run(t, dir, a, "./app")
+
+	a, err = NewAnalyzer(settings())
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	if err := a.Flags.Set(skipGeneratedFlag, "true"); err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	// The same package without counting the generated file is within both budgets
+	analysistest.Run(t, dir, a, "./generated")
+}
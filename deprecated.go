@@ -0,0 +1,105 @@
+package depguard
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+)
+
+// deprecatedRE finds the paragraph of a comment that starts with "Deprecated:",
+// the same way the go command reads it from go.mod files.
+var deprecatedRE = regexp.MustCompile(`(?s)(?:^|\n\n)Deprecated: *(.*?)(?:$|\n\n)`)
+
+// deprecation returns the deprecation message of a comment, empty if it has none.
+func deprecation(text string) string {
+	m := deprecatedRE.FindStringSubmatch(text)
+	if m == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(m[1]), " ")
+}
+
+// moduleDeprecations caches the deprecation message of every module directory.
+var moduleDeprecations sync.Map
+
+// moduleDeprecation returns the deprecation message of the module directive in
+// the go.mod file of a module directory, empty if it isn't deprecated.
+func moduleDeprecation(dir string) string {
+	if msg, ok := moduleDeprecations.Load(dir); ok {
+		return msg.(string)
+	}
+	var msg string
+	fileName := filepath.Join(dir, "go.mod")
+	if data, err := os.ReadFile(fileName); err == nil {
+		if mf, err := modfile.ParseLax(fileName, data, nil); err == nil {
+			msg = modFileDeprecation(mf)
+		}
+	}
+	moduleDeprecations.Store(dir, msg)
+	return msg
+}
+
+// modFileDeprecation returns the deprecation message of the module directive of a
+// go.mod file, empty if it isn't deprecated.
+func modFileDeprecation(mf *modfile.File) string {
+	if mf.Module == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(mf.Module.Deprecated), " ")
+}
+
+// latestModuleDeprecation returns the deprecation message of the required module
+// providing an import path, read from the go.mod of its latest downloaded version.
+// It is false when the import isn't from a required module or no version of it has
+// been downloaded.
+func latestModuleDeprecation(path string, versions map[string]string) (string, bool) {
+	mod, _ := requiredModule(path, versions)
+	if mod == "" {
+		return "", false
+	}
+	mf := latestModFile(mod)
+	if mf == nil {
+		return "", false
+	}
+	return modFileDeprecation(mf), true
+}
+
+// packageDeprecations caches the deprecation message of every package directory.
+var packageDeprecations sync.Map
+
+// packageDeprecation returns the deprecation message of the package doc comment
+// in a package directory, empty if it isn't deprecated.
+func packageDeprecation(dir string) string {
+	if msg, ok := packageDeprecations.Load(dir); ok {
+		return msg.(string)
+	}
+	var msg string
+	if entries, err := os.ReadDir(dir); err == nil {
+		var names []string
+		for _, e := range entries {
+			if name := e.Name(); !e.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		fset := token.NewFileSet()
+		for _, name := range names {
+			file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.PackageClauseOnly|parser.ParseComments)
+			if err != nil || file.Doc == nil {
+				continue
+			}
+			if msg = deprecation(file.Doc.Text()); msg != "" {
+				break
+			}
+		}
+	}
+	packageDeprecations.Store(dir, msg)
+	return msg
+}
@@ -0,0 +1,90 @@
+package depguard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeprecation(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		exp  string
+	}{
+		{name: "None", text: "Package foo does things.\n", exp: ""},
+		{name: "Only Paragraph", text: "Deprecated: use bar\ninstead.\n", exp: "use bar instead."},
+		{name: "Later Paragraph", text: "Package foo does things.\n\nDeprecated: use bar.\n\nMore docs.\n", exp: "use bar."},
+		{name: "Not At Start", text: "Package foo is not Deprecated: really.\n", exp: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if act := deprecation(tc.text); act != tc.exp {
+				t.Errorf("Deprecation didn't match expected: Exp %s: Act: %s", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestPackageDeprecation(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.go":      "package foo\n\nconst A = 1\n",
+		"doc.go":    "// Package foo does things.\n//\n// Deprecated: use bar\n// instead.\npackage foo\n",
+		"a_test.go": "// Deprecated: not this one.\npackage foo\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if act := packageDeprecation(dir); act != "use bar instead." {
+		t.Errorf("Deprecation didn't match expected: Exp use bar instead.: Act: %s", act)
+	}
+}
+
+func TestModuleDeprecation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("// Deprecated: use example.com/mod/v2\nmodule example.com/mod\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if act := moduleDeprecation(dir); act != "use example.com/mod/v2" {
+		t.Errorf("Deprecation didn't match expected: Exp use example.com/mod/v2: Act: %s", act)
+	}
+	if act := moduleDeprecation(t.TempDir()); act != "" {
+		t.Errorf("Deprecation didn't match expected: Exp none: Act: %s", act)
+	}
+}
+
+func TestLatestModFileDeprecation(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		// The required version isn't deprecated, only the later one is
+		"v1.0.0.mod": "module example.com/mod\n",
+		"v1.1.0.mod": "// Deprecated: use example.com/mod/v2\nmodule example.com/mod\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mf := readLatestModFile(dir)
+	if mf == nil {
+		t.Fatal("expected a go.mod file")
+	}
+	if act := modFileDeprecation(mf); act != "use example.com/mod/v2" {
+		t.Errorf("Deprecation didn't match expected: Exp use example.com/mod/v2: Act: %s", act)
+	}
+}
+
+func TestLatestModuleDeprecationUnresolved(t *testing.T) {
+	versions := map[string]string{"example.com/mod": "v1.0.0"}
+	for _, path := range []string{"fmt", "example.com/other/pkg"} {
+		if msg, ok := latestModuleDeprecation(path, versions); ok {
+			t.Errorf("expected no deprecation lookup for %s: %s", path, msg)
+		}
+	}
+	if _, looked := latestModFiles.Load(""); looked {
+		t.Error("expected imports outside of the required modules not to look up a go.mod file")
+	}
+}
@@ -1,9 +1,10 @@
 module github.com/OpenPeeDeeP/depguard/v2
 
-go 1.20
+go 1.21
 
 require (
 	github.com/gobwas/glob v0.2.3
+	golang.org/x/mod v0.16.0
 	golang.org/x/tools v0.19.0
 )
 
@@ -12,5 +13,3 @@ require (
 	github.com/google/go-cmp v0.6.0
 	gopkg.in/yaml.v3 v3.0.1
 )
-
-require golang.org/x/mod v0.16.0 // indirect
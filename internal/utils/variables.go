@@ -22,7 +22,10 @@ var (
 		"$test": &testExpander{},
 	}
 	PackageExpandable = ExpanderMap{
-		"$gostd": &gostdExpander{},
+		"$gostd":    &gostdExpander{},
+		"$cgo":      &cgoExpander{},
+		"$unsafe":   &unsafeExpander{},
+		"$testonly": &testOnlyExpander{},
 	}
 )
 
@@ -38,6 +41,34 @@ func (*testExpander) Expand() ([]string, error) {
 	return []string{"**/*_test.go"}, nil
 }
 
+type cgoExpander struct{}
+
+func (*cgoExpander) Expand() ([]string, error) {
+	return []string{"C$"}, nil
+}
+
+type unsafeExpander struct{}
+
+func (*unsafeExpander) Expand() ([]string, error) {
+	return []string{"unsafe$"}, nil
+}
+
+type testOnlyExpander struct{}
+
+// Packages like *testutil are matched on their name instead, see IsTestOnlyName.
+func (*testOnlyExpander) Expand() ([]string, error) {
+	return []string{
+		"github.com/stretchr/testify",
+		"net/http/httptest",
+		"testing",
+	}, nil
+}
+
+// IsTestOnlyName reports whether the last element of a package path names a test helper package.
+func IsTestOnlyName(pkg string) bool {
+	return strings.HasSuffix(path.Base(pkg), "testutil")
+}
+
 type gostdExpander struct {
 	cache []string
 }
@@ -97,7 +128,7 @@ func ExpandSlice(sl []string, exp ExpanderMap) ([]string, error) {
 	return sl, nil
 }
 
-func ExpandMap(m map[string]string, exp ExpanderMap) error {
+func ExpandMap[V any](m map[string]V, exp ExpanderMap) error {
 	for k, v := range m {
 		f, found := exp[k]
 		if !found {
@@ -51,6 +51,51 @@ func TestTestExpander(t *testing.T) {
 	}
 }
 
+func TestCgoExpander(t *testing.T) {
+	exp := &cgoExpander{}
+	pre, err := exp.Expand()
+	if err != nil {
+		t.Fatal("expansion method returned an error")
+	}
+	if diff := cmp.Diff([]string{"C$"}, pre); diff != "" {
+		t.Errorf("did not expand to the C pseudo package\n%s", diff)
+	}
+}
+
+func TestUnsafeExpander(t *testing.T) {
+	exp := &unsafeExpander{}
+	pre, err := exp.Expand()
+	if err != nil {
+		t.Fatal("expansion method returned an error")
+	}
+	if diff := cmp.Diff([]string{"unsafe$"}, pre); diff != "" {
+		t.Errorf("did not expand to the unsafe package\n%s", diff)
+	}
+}
+
+func TestTestOnlyExpander(t *testing.T) {
+	exp := &testOnlyExpander{}
+	pre, err := exp.Expand()
+	if err != nil {
+		t.Fatal("expansion method returned an error")
+	}
+	if !contains(pre, "testing") || !contains(pre, "net/http/httptest") {
+		t.Error("could not find some of the expected packages")
+	}
+}
+
+func TestIsTestOnlyName(t *testing.T) {
+	if !IsTestOnlyName("example.com/internal/testutil") {
+		t.Error("testutil should be test only")
+	}
+	if !IsTestOnlyName("example.com/pkg/dbtestutil") {
+		t.Error("dbtestutil should be test only")
+	}
+	if IsTestOnlyName("example.com/testutil/db") {
+		t.Error("only the last element should be checked")
+	}
+}
+
 func TestGoStdExpander(t *testing.T) {
 	exp := &gostdExpander{}
 	pre, err := exp.Expand()
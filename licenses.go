@@ -0,0 +1,148 @@
+package depguard
+
+import (
+	"bufio"
+	"bytes"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/module"
+)
+
+// licensePatterns identify the SPDX id of a license file from its text. Licenses
+// that have a title are matched on it at the start of the file, as their texts
+// mention other licenses, and the others on the text that sets them apart.
+var licensePatterns = []struct {
+	id      string
+	pattern *regexp.Regexp
+}{
+	{id: "AGPL-3.0", pattern: regexp.MustCompile(`(?i)^\s*GNU AFFERO GENERAL PUBLIC LICENSE\s+Version 3\b`)},
+	{id: "LGPL-3.0", pattern: regexp.MustCompile(`(?i)^\s*GNU LESSER GENERAL PUBLIC LICENSE\s+Version 3\b`)},
+	{id: "LGPL-2.1", pattern: regexp.MustCompile(`(?i)^\s*GNU LESSER GENERAL PUBLIC LICENSE\s+Version 2\.1\b`)},
+	{id: "MPL-2.0", pattern: regexp.MustCompile(`(?i)^\s*Mozilla Public License,?\s+Version 2\.0\b`)},
+	{id: "GPL-3.0", pattern: regexp.MustCompile(`(?i)^\s*GNU GENERAL PUBLIC LICENSE\s+Version 3\b`)},
+	{id: "GPL-2.0", pattern: regexp.MustCompile(`(?i)^\s*GNU GENERAL PUBLIC LICENSE\s+Version 2\b`)},
+	{id: "Apache-2.0", pattern: regexp.MustCompile(`(?i)^\s*Apache License\s+Version 2\.0\b`)},
+	{id: "MIT", pattern: regexp.MustCompile(`(?i)Permission is hereby granted, free of charge`)},
+	{id: "ISC", pattern: regexp.MustCompile(`(?i)Permission to use, copy, modify, and(/or)? distribute this software for any purpose`)},
+	{id: "BSD-3-Clause", pattern: regexp.MustCompile(`(?is)Redistribution and use in source and binary forms.*Neither the name`)},
+	{id: "BSD-2-Clause", pattern: regexp.MustCompile(`(?i)Redistribution and use in source and binary forms`)},
+	{id: "Unlicense", pattern: regexp.MustCompile(`(?i)This is free and unencumbered software released into the public domain`)},
+}
+
+// spdxTag is the tag a license file can use to name its license outright.
+const spdxTag = "SPDX-License-Identifier:"
+
+// identifyLicense returns the SPDX id of the license text, empty if it isn't known.
+func identifyLicense(text []byte) string {
+	scanner := bufio.NewScanner(bytes.NewReader(text))
+	for scanner.Scan() {
+		if _, id, found := strings.Cut(scanner.Text(), spdxTag); found {
+			return strings.TrimSpace(id)
+		}
+	}
+	for _, lp := range licensePatterns {
+		if lp.pattern.Match(text) {
+			return lp.id
+		}
+	}
+	return ""
+}
+
+// isLicenseFile reports whether a file name is one a license is usually kept in.
+func isLicenseFile(name string) bool {
+	name = strings.ToUpper(name)
+	for _, prefix := range []string{"LICENSE", "LICENCE", "COPYING"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// licenses caches the license found in every module directory.
+var licenses sync.Map
+
+// findLicense returns the SPDX id of the license in the root of a module directory,
+// empty if no license file is found or it isn't known.
+func findLicense(dir string) string {
+	if id, ok := licenses.Load(dir); ok {
+		return id.(string)
+	}
+	var id string
+	if entries, err := os.ReadDir(dir); err == nil {
+		var names []string
+		for _, e := range entries {
+			if !e.IsDir() && isLicenseFile(e.Name()) {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			text, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				continue
+			}
+			if id = identifyLicense(text); id != "" {
+				break
+			}
+		}
+	}
+	licenses.Store(dir, id)
+	return id
+}
+
+// moduleSourceDir returns the directory holding the module that provides an import
+// path, looking in the vendor directory of the importing module, then the directory
+// or module it is replaced with, then the module cache for the required version and
+// last where the loaded package lives. It is empty when the module can't be found.
+func moduleSourceDir(fset *token.FileSet, pkg *types.Package, path, moduleRoot string, versions map[string]string) string {
+	mod, version := requiredModule(path, versions)
+	if mod != "" {
+		if moduleRoot != "" {
+			dir := filepath.Join(moduleRoot, "vendor", filepath.FromSlash(mod))
+			if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+				return dir
+			}
+		}
+		rep := replacement(moduleRoot, mod, version)
+		if isLocalReplace(rep) {
+			dir := rep.New.Path
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(moduleRoot, dir)
+			}
+			if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+				return dir
+			}
+		} else if rep != nil {
+			mod, version = rep.New.Path, rep.New.Version
+		}
+		if cache := goModCache(); cache != "" {
+			escPath, perr := module.EscapePath(mod)
+			escVersion, verr := module.EscapeVersion(version)
+			if perr == nil && verr == nil {
+				dir := filepath.Join(cache, filepath.FromSlash(escPath)+"@"+escVersion)
+				if fi, err := os.Stat(dir); err == nil && fi.IsDir() {
+					return dir
+				}
+			}
+		}
+	}
+	if pkg == nil {
+		return ""
+	}
+	dir := packageDir(fset, pkg)
+	if dir == "" {
+		return ""
+	}
+	if root := findModuleRoot(dir); root != "" && root != moduleRoot {
+		return root
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package depguard
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIdentifyLicense(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		exp  string
+	}{
+		{name: "SPDX", text: "// SPDX-License-Identifier: EUPL-1.2\n", exp: "EUPL-1.2"},
+		{name: "AGPL", text: "                    GNU AFFERO GENERAL PUBLIC LICENSE\n                       Version 3, 19 November 2007", exp: "AGPL-3.0"},
+		{name: "GPL Mentioned After Title", text: "Copyright (c) 2020\n\nGNU GENERAL PUBLIC LICENSE\nVersion 3", exp: ""},
+		{name: "Unknown", text: "All rights reserved.", exp: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if act := identifyLicense([]byte(tc.text)); act != tc.exp {
+				t.Errorf("License didn't match expected: Exp %s: Act: %s", tc.exp, act)
+			}
+		})
+	}
+}
+
+// TestIdentifyLicenseTexts identifies the full texts of the licenses in testdata/licenses,
+// which are named after their SPDX id.
+func TestIdentifyLicenseTexts(t *testing.T) {
+	entries, err := os.ReadDir(filepath.Join("testdata", "licenses"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		t.Run(e.Name(), func(t *testing.T) {
+			text, err := os.ReadFile(filepath.Join("testdata", "licenses", e.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if act := identifyLicense(text); act != e.Name() {
+				t.Errorf("License didn't match expected: Exp %s: Act: %s", e.Name(), act)
+			}
+		})
+	}
+}
+
+func TestFindLicense(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("Permission is hereby granted, free of charge"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if act := findLicense(dir); act != "" {
+		t.Errorf("License didn't match expected: Exp none: Act: %s", act)
+	}
+	dir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "LICENSE.txt"), []byte("Permission is hereby granted, free of charge"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if act := findLicense(dir); act != "MIT" {
+		t.Errorf("License didn't match expected: Exp MIT: Act: %s", act)
+	}
+}
+
+func TestModuleSourceDir(t *testing.T) {
+	root := t.TempDir()
+	vendored := filepath.Join(root, "vendor", "example.com", "dep")
+	if err := os.MkdirAll(vendored, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	versions := map[string]string{"example.com/dep": "v1.0.0"}
+	fset := token.NewFileSet()
+	if act := moduleSourceDir(fset, nil, "example.com/dep/pkg", root, versions); act != vendored {
+		t.Errorf("Directory didn't match expected: Exp %s: Act: %s", vendored, act)
+	}
+	if act := moduleSourceDir(fset, nil, "example.com/other", root, versions); act != "" {
+		t.Errorf("Directory didn't match expected: Exp none: Act: %s", act)
+	}
+}
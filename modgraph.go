@@ -0,0 +1,173 @@
+package depguard
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// moduleGraphFact is exported for every package when module rules are in use.
+// It holds the module level dependency graph of the package and everything it imports.
+type moduleGraphFact struct {
+	// Module is the path of the module the package belongs to, empty when unknown.
+	Module string
+	// Edges maps a module to the modules it depends on, each sorted.
+	Edges map[string][]string
+}
+
+func (*moduleGraphFact) AFact() {}
+
+func (f *moduleGraphFact) String() string {
+	return fmt.Sprintf("module %s with %d dependencies", f.Module, len(f.Edges))
+}
+
+// addEdge records that module from depends on module to.
+func (f *moduleGraphFact) addEdge(from, to string) {
+	deps := f.Edges[from]
+	idx := sort.SearchStrings(deps, to)
+	if idx < len(deps) && deps[idx] == to {
+		return
+	}
+	deps = append(deps, "")
+	copy(deps[idx+1:], deps[idx:])
+	deps[idx] = to
+	f.Edges[from] = deps
+}
+
+// path returns the modules walked to get from one module to the other, nil if there is no way.
+func (f *moduleGraphFact) path(from, to string) []string {
+	prev := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == to {
+			var p []string
+			for m := to; m != ""; m = prev[m] {
+				p = append([]string{m}, p...)
+			}
+			return p
+		}
+		for _, next := range f.Edges[cur] {
+			if _, seen := prev[next]; !seen {
+				prev[next] = cur
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// usesModuleGraph reports whether any of the lists needs the module graph facts.
+func (s linterSettings) usesModuleGraph() bool {
+	for _, l := range s {
+		if l.denyModuleCycles || len(l.moduleOrder) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// setFactTypes declares the facts the analyzer exports for the compiled settings.
+func (s linterSettings) setFactTypes(a *analysis.Analyzer) {
+	a.FactTypes = nil
+	if s.usesModuleGraph() {
+		a.FactTypes = []analysis.Fact{new(moduleGraphFact)}
+	}
+}
+
+// usesModuleGraph reports whether any of the lists as configured needs the module
+// graph facts, for the analyzers that only compile the settings when they run.
+func (set LinterSettings) usesModuleGraph() bool {
+	for _, l := range set {
+		if l != nil && (l.DenyModuleCycles || len(l.ModuleOrder) > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// setFactTypes declares the facts the analyzer exports for the settings as configured.
+func (set LinterSettings) setFactTypes(a *analysis.Analyzer) {
+	a.FactTypes = nil
+	if set.usesModuleGraph() {
+		a.FactTypes = []analysis.Fact{new(moduleGraphFact)}
+	}
+}
+
+// errMissingModuleGraph is returned when module rules are configured but the analyzer
+// running them does not declare the module graph fact, which would otherwise leave
+// the rules silently unchecked.
+var errMissingModuleGraph = errors.New("moduleOrder and denyModuleCycles need the analyzer to declare the module graph fact, create it with NewAnalyzer or NewUncompiledAnalyzer")
+
+// moduleGraph builds the module graph fact for the package being analyzed and
+// exports it. It also returns the module of every package it imports that has one.
+// The graph holds the imports between modules as seen through the packages, and the
+// requirements declared in the go.mod files of modules outside of the module cache.
+func moduleGraph(pass *analysis.Pass, moduleRoot string) (*moduleGraphFact, map[string]string) {
+	fact := &moduleGraphFact{Edges: map[string][]string{}}
+	local := moduleRoot != "" && !inModuleCache(moduleRoot)
+	if moduleRoot != "" {
+		fact.Module = findModulePath(moduleRoot)
+		if fact.Module == "std" {
+			fact.Module = ""
+		}
+	}
+	if local && fact.Module != "" {
+		for _, req := range requiredModules(moduleRoot) {
+			fact.addEdge(fact.Module, req)
+		}
+	}
+	imported := make(map[string]string)
+	for _, pkg := range pass.Pkg.Imports() {
+		var dep moduleGraphFact
+		if !pass.ImportPackageFact(pkg, &dep) {
+			continue
+		}
+		for from, deps := range dep.Edges {
+			for _, to := range deps {
+				fact.addEdge(from, to)
+			}
+		}
+		if dep.Module == "" {
+			continue
+		}
+		imported[pkg.Path()] = dep.Module
+		if local && fact.Module != "" && dep.Module != fact.Module {
+			fact.addEdge(fact.Module, dep.Module)
+		}
+	}
+	pass.ExportPackageFact(fact)
+	return fact, imported
+}
+
+// packageModuleRoot returns the module root of the package the pass is analyzing.
+func packageModuleRoot(pass *analysis.Pass) string {
+	for _, file := range pass.Files {
+		fileName := pass.Fset.Position(file.Pos()).Filename
+		if root := findModuleRoot(filepath.Dir(fileName)); root != "" {
+			return root
+		}
+	}
+	return ""
+}
+
+// hasFactType reports whether the analyzer of the pass declared the fact.
+func hasFactType(pass *analysis.Pass, fact analysis.Fact) bool {
+	for _, f := range pass.Analyzer.FactTypes {
+		if reflect.TypeOf(f) == reflect.TypeOf(fact) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatModulePath renders a path through the module graph for a message.
+func formatModulePath(p []string) string {
+	return strings.Join(p, " -> ")
+}
@@ -0,0 +1,49 @@
+package depguard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestModuleGraphFactAddEdge(t *testing.T) {
+	f := &moduleGraphFact{Edges: map[string][]string{}}
+	f.addEdge("a", "c")
+	f.addEdge("a", "b")
+	f.addEdge("a", "c")
+	f.addEdge("b", "a")
+	exp := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"a"},
+	}
+	if diff := cmp.Diff(exp, f.Edges); diff != "" {
+		t.Errorf("Edges() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestModuleGraphFactPath(t *testing.T) {
+	f := &moduleGraphFact{Edges: map[string][]string{}}
+	f.addEdge("a", "b")
+	f.addEdge("b", "c")
+	f.addEdge("a", "c")
+	f.addEdge("c", "d")
+	tests := []struct {
+		from string
+		to   string
+		exp  []string
+	}{
+		{from: "a", to: "d", exp: []string{"a", "c", "d"}},
+		{from: "b", to: "d", exp: []string{"b", "c", "d"}},
+		{from: "a", to: "a", exp: []string{"a"}},
+		{from: "d", to: "a", exp: nil},
+		{from: "e", to: "a", exp: nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.from+"->"+tc.to, func(t *testing.T) {
+			act := f.path(tc.from, tc.to)
+			if diff := cmp.Diff(tc.exp, act); diff != "" {
+				t.Errorf("path() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package depguard
+
+import (
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+)
+
+// moduleRoots caches the module root of every directory that has been looked up.
+var moduleRoots sync.Map
+
+// findModuleRoot returns the closest directory at or above dir that holds a go.mod file,
+// or an empty string if there is none.
+func findModuleRoot(dir string) string {
+	if root, ok := moduleRoots.Load(dir); ok {
+		return root.(string)
+	}
+	var root string
+	if fi, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil && !fi.IsDir() {
+		root = dir
+	} else if parent := filepath.Dir(dir); parent != dir {
+		root = findModuleRoot(parent)
+	}
+	moduleRoots.Store(dir, root)
+	return root
+}
+
+// goModCache is the directory of the module cache, empty if it can't be found.
+var goModCache = sync.OnceValue(func() string {
+	if env := os.Getenv("GOMODCACHE"); env != "" {
+		return filepath.Clean(env)
+	}
+	out, err := exec.Command("go", "env", "GOMODCACHE").Output()
+	if err != nil {
+		return ""
+	}
+	return filepath.Clean(strings.TrimSpace(string(out)))
+})
+
+// inModuleCache reports whether the directory is inside of the module cache.
+func inModuleCache(dir string) bool {
+	cache := goModCache()
+	return cache != "" && cache != "." && strings.HasPrefix(dir, cache+string(filepath.Separator))
+}
+
+// modulePaths caches the module path declared by the go.mod file of every module root.
+var modulePaths sync.Map
+
+// findModulePath returns the module path declared in the go.mod file in root,
+// or an empty string if it can not be read.
+func findModulePath(root string) string {
+	if root == "" {
+		return ""
+	}
+	if mp, ok := modulePaths.Load(root); ok {
+		return mp.(string)
+	}
+	var mp string
+	if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		mp = modfile.ModulePath(data)
+	}
+	modulePaths.Store(root, mp)
+	return mp
+}
+
+// goModFiles caches the parsed go.mod file of every module root.
+var goModFiles sync.Map
+
+// goModFile returns the parsed go.mod file in root, nil if it can not be read.
+func goModFile(root string) *modfile.File {
+	if root == "" {
+		return nil
+	}
+	if mf, ok := goModFiles.Load(root); ok {
+		return mf.(*modfile.File)
+	}
+	var mf *modfile.File
+	fileName := filepath.Join(root, "go.mod")
+	if data, err := os.ReadFile(fileName); err == nil {
+		// ParseLax leaves out replace directives, it is only a fallback for files newer versions of go wrote
+		var perr error
+		if mf, perr = modfile.Parse(fileName, data, nil); perr != nil {
+			mf, _ = modfile.ParseLax(fileName, data, nil)
+		}
+	}
+	goModFiles.Store(root, mf)
+	return mf
+}
+
+// requiredVersionsCache caches the requirements of every module root.
+var requiredVersionsCache sync.Map
+
+// requiredVersions returns the version of every module required by the go.mod file in root.
+func requiredVersions(root string) map[string]string {
+	if root == "" {
+		return nil
+	}
+	if reqs, ok := requiredVersionsCache.Load(root); ok {
+		return reqs.(map[string]string)
+	}
+	var reqs map[string]string
+	if mf := goModFile(root); mf != nil {
+		reqs = make(map[string]string, len(mf.Require))
+		for _, r := range mf.Require {
+			reqs[r.Mod.Path] = r.Mod.Version
+		}
+	}
+	requiredVersionsCache.Store(root, reqs)
+	return reqs
+}
+
+// requiredModules returns the modules required by the go.mod file in root, sorted.
+func requiredModules(root string) []string {
+	reqs := requiredVersions(root)
+	mods := make([]string, 0, len(reqs))
+	for m := range reqs {
+		mods = append(mods, m)
+	}
+	sort.Strings(mods)
+	return mods
+}
+
+// packageDir returns the directory holding the source of a loaded package, taken
+// from the position of its objects. It is empty when positions are not known.
+func packageDir(fset *token.FileSet, pkg *types.Package) string {
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if pos := scope.Lookup(name).Pos(); pos.IsValid() {
+			if fileName := fset.Position(pos).Filename; filepath.IsAbs(fileName) {
+				return filepath.Dir(fileName)
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// moduleDir returns the slash separated directory of a loaded package relative to
+// the root of the module it belongs to.
+func moduleDir(fset *token.FileSet, pkg *types.Package) (string, bool) {
+	dir := packageDir(fset, pkg)
+	if dir == "" {
+		return "", false
+	}
+	root := findModuleRoot(dir)
+	if root == "" {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// isStdLib reports whether an import path belongs to the standard library. Every
+// other package is prefixed with a domain, so its first element has a dot.
+func isStdLib(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// inModule reports whether an import path is provided by the module.
+func inModule(path, modulePath string) bool {
+	return modulePath != "" && (path == modulePath || strings.HasPrefix(path, modulePath+"/"))
+}
+
+// importModule returns the module providing an import path: the longest of the
+// requirements it is in, else the module found from where the package lives,
+// else the import path itself.
+func importModule(fset *token.FileSet, pkg *types.Package, path string, requires []string) string {
+	var mod string
+	for _, r := range requires {
+		if inModule(path, r) && len(r) > len(mod) {
+			mod = r
+		}
+	}
+	if mod != "" {
+		return mod
+	}
+	if pkg != nil {
+		if dir := packageDir(fset, pkg); dir != "" {
+			if mp := findModulePath(findModuleRoot(dir)); mp != "" {
+				return mp
+			}
+		}
+	}
+	return path
+}
@@ -0,0 +1,95 @@
+package depguard
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindModuleRoot(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "pkg", "foo")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/mod\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if act := findModuleRoot(nested); act != root {
+		t.Errorf("module root did not match expected: Exp %s: Act: %s", root, act)
+	}
+	if act := findModuleRoot(root); act != root {
+		t.Errorf("module root did not match expected: Exp %s: Act: %s", root, act)
+	}
+	if act := findModulePath(root); act != "example.com/mod" {
+		t.Errorf("module path did not match expected: Exp example.com/mod: Act: %s", act)
+	}
+}
+
+func TestModuleDir(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/mod\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	fileName := filepath.Join(root, "cmd", "tool", "tool.go")
+	f, err := parser.ParseFile(fset, fileName, "package tool\n\nfunc Help() {}\n", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importer.Default()}
+	pkg, err := conf.Check("example.com/mod/cmd/tool", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, ok := moduleDir(fset, pkg)
+	if !ok {
+		t.Fatal("expected the directory to be known")
+	}
+	if dir != "cmd/tool" {
+		t.Errorf("module directory did not match expected: Exp cmd/tool: Act: %s", dir)
+	}
+	if _, ok := moduleDir(fset, types.NewPackage("example.com/empty", "empty")); ok {
+		t.Error("expected the directory of a package without objects to be unknown")
+	}
+}
+
+func TestIsStdLib(t *testing.T) {
+	tests := map[string]bool{
+		"os":                           true,
+		"net/http":                     true,
+		"C":                            true,
+		"golang.org/x/mod/modfile":     false,
+		"github.com/gobwas/glob":       false,
+		"example.com":                  false,
+		"internal/example.com/private": true,
+	}
+	for path, exp := range tests {
+		if act := isStdLib(path); act != exp {
+			t.Errorf("isStdLib(%s) did not match expected: Exp %t: Act: %t", path, exp, act)
+		}
+	}
+}
+
+func TestImportModule(t *testing.T) {
+	requires := []string{"example.com/mod", "example.com/mod/v2", "example.com/mod/sub"}
+	tests := map[string]string{
+		"example.com/mod":           "example.com/mod",
+		"example.com/mod/pkg":       "example.com/mod",
+		"example.com/mod/v2/pkg":    "example.com/mod/v2",
+		"example.com/mod/sub/pkg":   "example.com/mod/sub",
+		"example.com/module/pkg":    "example.com/module/pkg",
+		"golang.org/x/mod/semver":   "golang.org/x/mod/semver",
+		"example.com/mod/subtle/go": "example.com/mod",
+	}
+	for path, exp := range tests {
+		if act := importModule(token.NewFileSet(), nil, path, requires); act != exp {
+			t.Errorf("importModule(%s) did not match expected: Exp %s: Act: %s", path, exp, act)
+		}
+	}
+}
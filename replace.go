@@ -0,0 +1,103 @@
+package depguard
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+)
+
+// replacement returns the replace directive of the go.mod file in root that applies
+// to a module at a version, nil if the module isn't replaced. A replacement of the
+// exact version wins over one for every version.
+func replacement(root, mod, version string) *modfile.Replace {
+	mf := goModFile(root)
+	if mf == nil {
+		return nil
+	}
+	var rep *modfile.Replace
+	for _, r := range mf.Replace {
+		if r.Old.Path != mod {
+			continue
+		}
+		if r.Old.Version == version {
+			return r
+		}
+		if r.Old.Version == "" {
+			rep = r
+		}
+	}
+	return rep
+}
+
+// isLocalReplace reports whether a replace directive points at a directory instead of a module.
+func isLocalReplace(rep *modfile.Replace) bool {
+	return rep != nil && rep.New.Version == "" && modfile.IsDirectoryPath(rep.New.Path)
+}
+
+// replacedPath returns the import path a package of a module has in its replacement,
+// empty when it isn't replaced by another module.
+func replacedPath(path, mod string, rep *modfile.Replace) string {
+	if rep == nil || isLocalReplace(rep) || rep.New.Path == mod {
+		return ""
+	}
+	return rep.New.Path + strings.TrimPrefix(path, mod)
+}
+
+// resolvedImport returns the import path, module and version that provide an import
+// after the replace directives of the go.mod file in root. They are all empty when the
+// import isn't required or its module is replaced with a local directory.
+func resolvedImport(root, path string, versions map[string]string) (string, string, string) {
+	mod, version := requiredModule(path, versions)
+	if mod == "" {
+		return "", "", ""
+	}
+	rep := replacement(root, mod, version)
+	switch {
+	case rep == nil:
+		return path, mod, version
+	case isLocalReplace(rep):
+		return "", "", ""
+	default:
+		return rep.New.Path + strings.TrimPrefix(path, mod), rep.New.Path, rep.New.Version
+	}
+}
+
+// vendoredCache caches the vendored packages of every module root.
+var vendoredCache sync.Map
+
+// vendoredPackages returns the packages listed in the vendor/modules.txt file in root.
+func vendoredPackages(root string) map[string]bool {
+	if root == "" {
+		return nil
+	}
+	if pkgs, ok := vendoredCache.Load(root); ok {
+		return pkgs.(map[string]bool)
+	}
+	var pkgs map[string]bool
+	if f, err := os.Open(filepath.Join(root, "vendor", "modules.txt")); err == nil {
+		pkgs = readVendoredPackages(f)
+		f.Close()
+	}
+	vendoredCache.Store(root, pkgs)
+	return pkgs
+}
+
+// readVendoredPackages reads the package lines of a vendor/modules.txt file,
+// the ones that aren't comments starting with '#'.
+func readVendoredPackages(r io.Reader) map[string]bool {
+	pkgs := make(map[string]bool)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pkgs[line] = true
+	}
+	return pkgs
+}
@@ -0,0 +1,85 @@
+package depguard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testReplaceGoMod = `module example.com/app
+
+go 1.21
+
+require (
+	example.com/forked v1.0.0
+	example.com/local v1.0.0
+	example.com/pinned v1.2.0
+)
+
+replace example.com/forked => example.com/fork v1.0.1
+
+replace example.com/local => ../local
+
+replace (
+	example.com/pinned v1.1.0 => example.com/old v1.0.0
+	example.com/pinned v1.2.0 => example.com/new v1.0.0
+)
+`
+
+func TestResolvedImport(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(testReplaceGoMod), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	versions := requiredVersions(root)
+	tests := []struct {
+		path    string
+		resPath string
+		mod     string
+		version string
+	}{
+		{path: "example.com/forked/pkg", resPath: "example.com/fork/pkg", mod: "example.com/fork", version: "v1.0.1"},
+		{path: "example.com/local/pkg"},
+		{path: "example.com/pinned", resPath: "example.com/new", mod: "example.com/new", version: "v1.0.0"},
+		{path: "example.com/other"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.path, func(t *testing.T) {
+			resPath, mod, version := resolvedImport(root, tc.path, versions)
+			if resPath != tc.resPath || mod != tc.mod || version != tc.version {
+				t.Errorf("resolvedImport() didn't match expected: Exp %s %s %s: Act: %s %s %s",
+					tc.resPath, tc.mod, tc.version, resPath, mod, version)
+			}
+		})
+	}
+	if !isLocalReplace(replacement(root, "example.com/local", "v1.0.0")) {
+		t.Error("expected example.com/local to be replaced with a local directory")
+	}
+	rep := replacement(root, "example.com/forked", "v1.0.0")
+	if act := replacedPath("example.com/forked/pkg", "example.com/forked", rep); act != "example.com/fork/pkg" {
+		t.Errorf("replacedPath() didn't match expected: Exp example.com/fork/pkg: Act: %s", act)
+	}
+}
+
+func TestReadVendoredPackages(t *testing.T) {
+	modules := `# example.com/lib v1.0.0 => ../lib
+## explicit; go 1.21
+example.com/lib
+example.com/lib/sub
+# golang.org/x/mod v0.16.0
+## explicit; go 1.18
+golang.org/x/mod/semver
+# example.com/lib => ../lib
+`
+	exp := map[string]bool{
+		"example.com/lib":         true,
+		"example.com/lib/sub":     true,
+		"golang.org/x/mod/semver": true,
+	}
+	if diff := cmp.Diff(exp, readVendoredPackages(strings.NewReader(modules))); diff != "" {
+		t.Errorf("readVendoredPackages() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,60 @@
+package depguard
+
+import "go/token"
+
+// Result is what the analyzer returns for every package it checks, for tools
+// that need more than the diagnostics.
+type Result struct {
+	// Imports holds the verdict of every list on every import it checked.
+	Imports []*ImportResult
+	// Violations holds everything the lists did not allow, in the order it was reported.
+	Violations []*Violation
+	// CgoFiles are the names of the files that import "C", and so introduce cgo, using
+	// '/' as the separator. Files are listed whether or not a list checks them.
+	CgoFiles []string
+}
+
+// ImportResult is the verdict of a list on an import, from its allow and deny entries.
+type ImportResult struct {
+	Pos token.Pos
+	// File is the name of the file the import is in, using '/' as the separator.
+	File     string
+	Importer string
+	Import   string
+	List     string
+	Allowed  bool
+	// Rule is the allow or deny entry that decided the verdict, if any.
+	Rule string
+	// AllowEntry and DenyEntry are the allow and deny entries of the list, as they are
+	// configured, that matched the import whether or not they decided the verdict.
+	AllowEntry string
+	DenyEntry  string
+}
+
+// Violation is something a list did not allow, along with what the diagnostic was made from.
+type Violation struct {
+	Pos token.Pos
+	// File is the name of the file the violation is in, using '/' as the separator.
+	File string
+	// Import is the import path of the package the violation is about, empty for
+	// import budgets which are about the package being checked.
+	Import string
+	// Symbol is set for denied symbols.
+	Symbol string
+	// Kind describes which check the violation is from, empty for the allow and deny entries.
+	Kind       string
+	List       string
+	Mode       string
+	Rule       string
+	Suggestion string
+	// Severity is error, warning or info, as configured for the list.
+	Severity string
+	// Owner, URL and Reason are the metadata of the matched deny entry or the list.
+	Owner   string
+	URL     string
+	Reason  string
+	Message string
+	// Related are the positions of what makes up the violation, like the imports
+	// counted against a budget.
+	Related []token.Pos
+}
@@ -0,0 +1,114 @@
+package depguard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// retractions returns the retract directives of a module. Retractions are published
+// in later versions, so like the go command they are read from the go.mod of the
+// latest version rather than the one that is required.
+func retractions(mod string) []*modfile.Retract {
+	if mf := latestModFile(mod); mf != nil {
+		return mf.Retract
+	}
+	return nil
+}
+
+// latestModFiles caches the go.mod of the latest downloaded version of every module.
+var latestModFiles sync.Map
+
+// latestModFile returns the go.mod of the latest version of a module the module
+// cache has downloaded, nil if it has none.
+func latestModFile(mod string) *modfile.File {
+	if mf, ok := latestModFiles.Load(mod); ok {
+		return mf.(*modfile.File)
+	}
+	var mf *modfile.File
+	if dir := downloadDir(mod); dir != "" {
+		mf = readLatestModFile(dir)
+	}
+	latestModFiles.Store(mod, mf)
+	return mf
+}
+
+// readLatestModFile parses the go.mod file of the highest version in a download
+// directory, where releases come before pre-releases like they do for the go command.
+func readLatestModFile(dir string) *modfile.File {
+	entries, _ := os.ReadDir(dir)
+	var latest, latestName string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || filepath.Ext(name) != ".mod" {
+			continue
+		}
+		version, err := module.UnescapeVersion(strings.TrimSuffix(name, ".mod"))
+		if err != nil || !semver.IsValid(version) {
+			continue
+		}
+		if latest == "" || laterVersion(version, latest) {
+			latest, latestName = version, name
+		}
+	}
+	if latest == "" {
+		return nil
+	}
+	fileName := filepath.Join(dir, latestName)
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil
+	}
+	mf, err := modfile.ParseLax(fileName, data, nil)
+	if err != nil {
+		return nil
+	}
+	return mf
+}
+
+// laterVersion reports whether version v is later than version than, counting any
+// release as later than a pre-release.
+func laterVersion(v, than string) bool {
+	if release := semver.Prerelease(v) == ""; release != (semver.Prerelease(than) == "") {
+		return release
+	}
+	return semver.Compare(v, than) > 0
+}
+
+// downloadDir returns the directory the module cache keeps the downloaded versions
+// of a module in, empty if it can't be found.
+func downloadDir(mod string) string {
+	cache := goModCache()
+	if cache == "" {
+		return ""
+	}
+	escPath, err := module.EscapePath(mod)
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cache, "cache", "download", filepath.FromSlash(escPath), "@v")
+}
+
+// retraction returns the retraction that covers the version, nil if it isn't retracted.
+func retraction(retracts []*modfile.Retract, version string) *modfile.Retract {
+	for _, r := range retracts {
+		if semver.Compare(r.Low, version) <= 0 && semver.Compare(version, r.High) <= 0 {
+			return r
+		}
+	}
+	return nil
+}
+
+// retractedMessage describes a retracted version for a diagnostic.
+func retractedMessage(version string, r *modfile.Retract) string {
+	msg := "version " + version + " is retracted"
+	if rationale := strings.Join(strings.Fields(r.Rationale), " "); rationale != "" {
+		msg += ": " + rationale
+	}
+	return msg
+}
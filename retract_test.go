@@ -0,0 +1,72 @@
+package depguard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadLatestModFileRetractions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"v1.0.0.mod": "module example.com/mod\n",
+		"v1.0.2.mod": "module example.com/mod\n\n// Published with a data race.\nretract v1.0.1\n",
+		// The retraction of v1.0.1 was taken back in the latest version
+		"v1.2.0.mod":      "module example.com/mod\n\nretract (\n\t// Broke the API.\n\t[v1.1.0, v1.1.5]\n)\n",
+		"v1.3.0-rc.1.mod": "module example.com/mod\n\nretract v1.2.0\n",
+		"v1.2.0.info":     "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mf := readLatestModFile(dir)
+	if mf == nil {
+		t.Fatal("expected a go.mod file")
+	}
+	retracts := mf.Retract
+	if len(retracts) != 1 {
+		t.Fatalf("expected 1 retraction: got %d", len(retracts))
+	}
+	tests := []struct {
+		version string
+		exp     string
+	}{
+		{version: "v1.0.0", exp: ""},
+		{version: "v1.0.1", exp: ""},
+		{version: "v1.1.3", exp: "version v1.1.3 is retracted: Broke the API."},
+		{version: "v1.1.5", exp: "version v1.1.5 is retracted: Broke the API."},
+		{version: "v1.2.0", exp: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			var act string
+			if r := retraction(retracts, tc.version); r != nil {
+				act = retractedMessage(tc.version, r)
+			}
+			if act != tc.exp {
+				t.Errorf("Retraction didn't match expected: Exp %s: Act: %s", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestLaterVersion(t *testing.T) {
+	tests := []struct {
+		v    string
+		than string
+		exp  bool
+	}{
+		{v: "v1.2.0", than: "v1.1.0", exp: true},
+		{v: "v1.1.0", than: "v1.2.0", exp: false},
+		{v: "v1.2.0", than: "v1.3.0-rc.1", exp: true},
+		{v: "v1.3.0-rc.2", than: "v1.3.0-rc.1", exp: true},
+		{v: "v1.3.0-rc.1", than: "v1.0.0", exp: false},
+	}
+	for _, tc := range tests {
+		if act := laterVersion(tc.v, tc.than); act != tc.exp {
+			t.Errorf("%s later than %s didn't match expected: Exp %t: Act: %t", tc.v, tc.than, tc.exp, act)
+		}
+	}
+}
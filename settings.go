@@ -3,18 +3,103 @@ package depguard
 import (
 	"errors"
 	"fmt"
+	"go/build/constraint"
+	"go/token"
+	"io"
+	"path/filepath"
 	"sort"
 	"strings"
+	"text/template"
 
 	"github.com/OpenPeeDeeP/depguard/v2/internal/utils"
 	"github.com/gobwas/glob"
+	"golang.org/x/mod/modfile"
 )
 
 type List struct {
-	ListMode string            `json:"listMode" yaml:"listMode" toml:"listMode" mapstructure:"listMode"`
-	Files    []string          `json:"files" yaml:"files" toml:"files" mapstructure:"files"`
-	Allow    []string          `json:"allow" yaml:"allow" toml:"allow" mapstructure:"allow"`
-	Deny     map[string]string `json:"deny" yaml:"deny" toml:"deny" mapstructure:"deny"`
+	ListMode string   `json:"listMode" yaml:"listMode" toml:"listMode" mapstructure:"listMode"`
+	Files    []string `json:"files" yaml:"files" toml:"files" mapstructure:"files"`
+	Packages []string `json:"packages" yaml:"packages" toml:"packages" mapstructure:"packages"`
+	Root     string   `json:"root" yaml:"root" toml:"root" mapstructure:"root"`
+	// BuildTags select files by the platforms and tags they are constrained to,
+	// a comma requires all of the tags (`linux,amd64`).
+	BuildTags []string          `json:"buildTags" yaml:"buildTags" toml:"buildTags" mapstructure:"buildTags"`
+	Allow     []string          `json:"allow" yaml:"allow" toml:"allow" mapstructure:"allow"`
+	Deny      map[string]string `json:"deny" yaml:"deny" toml:"deny" mapstructure:"deny"`
+	Message   string            `json:"message" yaml:"message" toml:"message" mapstructure:"message"`
+	Owner     string            `json:"owner" yaml:"owner" toml:"owner" mapstructure:"owner"`
+	URL       string            `json:"url" yaml:"url" toml:"url" mapstructure:"url"`
+	Reason    string            `json:"reason" yaml:"reason" toml:"reason" mapstructure:"reason"`
+	// Severity is how bad the violations of the list are for reports: error (the default), warning or info.
+	Severity string `json:"severity" yaml:"severity" toml:"severity" mapstructure:"severity"`
+	// DenyMetadata overrides Owner, URL and Reason for individual deny entries.
+	DenyMetadata map[string]*Metadata `json:"denyMetadata" yaml:"denyMetadata" toml:"denyMetadata" mapstructure:"denyMetadata"`
+	// DenySymbols is a map of package level symbols (`net/http.Get`) that are not allowed
+	// where the value is a suggestion.
+	DenySymbols       map[string]string `json:"denySymbols" yaml:"denySymbols" toml:"denySymbols" mapstructure:"denySymbols"`
+	DenyDotImports    bool              `json:"denyDotImports" yaml:"denyDotImports" toml:"denyDotImports" mapstructure:"denyDotImports"`
+	DenyBlankImports  bool              `json:"denyBlankImports" yaml:"denyBlankImports" toml:"denyBlankImports" mapstructure:"denyBlankImports"`
+	AllowBlankImports []string          `json:"allowBlankImports" yaml:"allowBlankImports" toml:"allowBlankImports" mapstructure:"allowBlankImports"`
+	// DenyDirs is a list of directory globs, relative to the root of the module
+	// a package belongs to, that packages must not be imported from.
+	DenyDirs []string `json:"denyDirs" yaml:"denyDirs" toml:"denyDirs" mapstructure:"denyDirs"`
+	// Visibility is a map of package globs to the globs of packages that may import them.
+	Visibility map[string][]string `json:"visibility" yaml:"visibility" toml:"visibility" mapstructure:"visibility"`
+	// ModuleOrder is a list of module globs from the lowest layer to the highest,
+	// a module may not import modules from a higher layer.
+	ModuleOrder []string `json:"moduleOrder" yaml:"moduleOrder" toml:"moduleOrder" mapstructure:"moduleOrder"`
+	// DenyModuleCycles reports imports that make modules depend on each other.
+	DenyModuleCycles bool `json:"denyModuleCycles" yaml:"denyModuleCycles" toml:"denyModuleCycles" mapstructure:"denyModuleCycles"`
+	// AllowLicenses is a list of SPDX license id globs imported modules must be licensed under.
+	AllowLicenses []string `json:"allowLicenses" yaml:"allowLicenses" toml:"allowLicenses" mapstructure:"allowLicenses"`
+	// DenyLicenses is a map of SPDX license id globs imported modules must not be
+	// licensed under, where the value is a suggestion.
+	DenyLicenses map[string]string `json:"denyLicenses" yaml:"denyLicenses" toml:"denyLicenses" mapstructure:"denyLicenses"`
+	// DenyDeprecated reports imports of packages and modules marked as deprecated by their authors.
+	DenyDeprecated bool `json:"denyDeprecated" yaml:"denyDeprecated" toml:"denyDeprecated" mapstructure:"denyDeprecated"`
+	// DenyLocalReplace reports imports of modules replaced with a local directory in go.mod.
+	DenyLocalReplace bool `json:"denyLocalReplace" yaml:"denyLocalReplace" toml:"denyLocalReplace" mapstructure:"denyLocalReplace"`
+	// RequireReplace is a map of modules to the fork go.mod must replace them with.
+	RequireReplace map[string]string `json:"requireReplace" yaml:"requireReplace" toml:"requireReplace" mapstructure:"requireReplace"`
+	// VendoredOnly is a list of packages that may only be imported when they are vendored.
+	VendoredOnly []string `json:"vendoredOnly" yaml:"vendoredOnly" toml:"vendoredOnly" mapstructure:"vendoredOnly"`
+	// DenyRetracted reports imports of modules whose required version has been retracted by their authors.
+	DenyRetracted bool `json:"denyRetracted" yaml:"denyRetracted" toml:"denyRetracted" mapstructure:"denyRetracted"`
+	// VulnDB is a directory of OSV vulnerability reports, imports of packages with
+	// known vulnerabilities at the required version are reported.
+	VulnDB string `json:"vulnDB" yaml:"vulnDB" toml:"vulnDB" mapstructure:"vulnDB"`
+	// MaxImports is the most packages outside of the standard library and the
+	// package's own module a package may import, zero for no limit.
+	MaxImports int `json:"maxImports" yaml:"maxImports" toml:"maxImports" mapstructure:"maxImports"`
+	// MaxExternalModules is the most modules other than its own a package may import from, zero for no limit.
+	MaxExternalModules int `json:"maxExternalModules" yaml:"maxExternalModules" toml:"maxExternalModules" mapstructure:"maxExternalModules"`
+	// DenyTestOnly reports packages that are only meant for tests ($testonly
+	// and *testutil packages) when imported from files that are not tests.
+	DenyTestOnly bool `json:"denyTestOnly" yaml:"denyTestOnly" toml:"denyTestOnly" mapstructure:"denyTestOnly"`
+	// Aliases is a map of packages to the aliases they may be imported as.
+	// Prefixing an alias with an exclamation mark `!` denies it instead.
+	Aliases map[string][]string `json:"aliases" yaml:"aliases" toml:"aliases" mapstructure:"aliases"`
+}
+
+// Metadata tells whoever is blocked by a rule why it exists and whom to ask about it.
+type Metadata struct {
+	Owner  string `json:"owner" yaml:"owner" toml:"owner" mapstructure:"owner"`
+	URL    string `json:"url" yaml:"url" toml:"url" mapstructure:"url"`
+	Reason string `json:"reason" yaml:"reason" toml:"reason" mapstructure:"reason"`
+}
+
+// merge returns a copy of m where every field set in o takes precedence.
+func (m Metadata) merge(o Metadata) Metadata {
+	if o.Owner != "" {
+		m.Owner = o.Owner
+	}
+	if o.URL != "" {
+		m.URL = o.URL
+	}
+	if o.Reason != "" {
+		m.Reason = o.Reason
+	}
+	return m
 }
 
 type listMode int
@@ -25,21 +110,183 @@ const (
 	listModeLax
 )
 
+// The severities a list can report its violations with.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+	severityInfo    = "info"
+)
+
+func (lm listMode) String() string {
+	switch lm {
+	case listModeOriginal:
+		return "Original"
+	case listModeStrict:
+		return "Strict"
+	case listModeLax:
+		return "Lax"
+	default:
+		return "Unknown"
+	}
+}
+
+// defaultMessage is used for lists that do not define their own message template.
+var defaultMessage = template.Must(template.New("message").Parse(
+	"{{with .Kind}}{{.}} {{end}}import '{{.Import}}' is not allowed from list '{{.List}}'{{with .Suggestion}}: {{.}}{{end}}" +
+		"{{with .Reason}} [reason: {{.}}]{{end}}{{with .Owner}} [owner: {{.}}]{{end}}{{with .URL}} [url: {{.}}]{{end}}",
+))
+
+// defaultSymbolMessage is used for denied symbols in lists that do not define their own message template.
+var defaultSymbolMessage = template.Must(template.New("message").Parse(
+	"use of '{{.Symbol}}' is not allowed from list '{{.List}}'{{with .Suggestion}}: {{.}}{{end}}" +
+		"{{with .Reason}} [reason: {{.}}]{{end}}{{with .Owner}} [owner: {{.}}]{{end}}{{with .URL}} [url: {{.}}]{{end}}",
+))
+
+// defaultBudgetMessage is used for packages over an import budget in lists that do not define their own message template.
+var defaultBudgetMessage = template.Must(template.New("message").Parse(
+	"package '{{.Package}}' has {{.Count}} {{.Kind}}, more than the {{.Limit}} allowed from list '{{.List}}'{{with .Suggestion}}: {{.}}{{end}}" +
+		"{{with .Reason}} [reason: {{.}}]{{end}}{{with .Owner}} [owner: {{.}}]{{end}}{{with .URL}} [url: {{.}}]{{end}}",
+))
+
+// messageData is what a list's message template is executed against.
+type messageData struct {
+	Import      string
+	Symbol      string
+	Alias       string
+	Kind        string
+	List        string
+	File        string
+	Suggestion  string
+	MatchedRule string
+	Mode        string
+	Owner       string
+	URL         string
+	Reason      string
+	// Version is the required version of the imported module, if known.
+	Version string
+	// Replacement is the import path of the package in the module go.mod replaces its module with.
+	Replacement string
+	// License is the SPDX id of the license of the imported module, for license checks.
+	License string
+	// Package, Count and Limit are only set for import budgets.
+	Package string
+	Count   int
+	Limit   int
+}
+
 type list struct {
-	listMode    listMode
-	name        string
-	files       []glob.Glob
-	negFiles    []glob.Glob
-	allow       []string
-	deny        []string
-	suggestions []string
+	listMode listMode
+	name     string
+	// severity is empty when not configured, which is severityError.
+	severity string
+	files    []glob.Glob
+	negFiles []glob.Glob
+	// generated and negGenerated are set by the $generated file variable.
+	generated    bool
+	negGenerated bool
+	packages     []glob.Glob
+	negPackages  []glob.Glob
+	buildTags    [][]string
+	negBuildTags [][]string
+	// root is the absolute directory relative file globs are matched from,
+	// empty for the module root of each file.
+	root  string
+	allow []string
+	// allowVersions and denyVersions hold the version constraints of the entries that have one.
+	allowVersions map[string]*versionConstraint
+	denyVersions  map[string]*versionConstraint
+	// allowVariables and denyVariables map the entries package variables expanded to back
+	// to the variable, and are only populated when variables are used.
+	allowVariables map[string]string
+	denyVariables  map[string]string
+	deny           []string
+	suggestions    []string
+	message        *template.Template
+	metadata       Metadata
+	// denyMetadata matches the deny order and is only populated when entries have metadata.
+	denyMetadata      []Metadata
+	denySymbols       []string
+	symbolSuggestions []string
+	denyDotImports    bool
+	restrictBlank     bool
+	allowBlank        []string
+	aliases           map[string]*aliasRule
+	denyDirs          []*dirRule
+	visibility        []*visibilityRule
+	moduleOrder       []*dirRule
+	denyModuleCycles  bool
+	maxImports        int
+	maxModules        int
+	allowLicenses     []*licenseRule
+	denyLicenses      []*licenseRule
+	denyDeprecated    bool
+	denyRetracted     bool
+	denyLocalReplace  bool
+	requireReplace    map[string]string
+	vendoredOnly      []string
+	// vulnDB is the absolute directory of the vulnerability database, empty when not set.
+	vulnDB string
+	// testFiles and testOnly are only populated when test only packages are denied.
+	testFiles []glob.Glob
+	testOnly  []string
+}
+
+// dirRule is a compiled entry of DenyDirs or ModuleOrder.
+type dirRule struct {
+	pattern string
+	glob    glob.Glob
+}
+
+// licenseRule is a compiled entry of AllowLicenses or DenyLicenses.
+type licenseRule struct {
+	pattern    string
+	glob       glob.Glob
+	suggestion string
+}
+
+// visibilityRule is a compiled entry of Visibility.
+type visibilityRule struct {
+	pattern   string
+	glob      glob.Glob
+	importers []string
+	globs     []glob.Glob
+}
+
+// aliasRule holds the aliases a single package may and may not be imported as.
+type aliasRule struct {
+	allowed []string
+	denied  []string
+}
+
+// importVerdict is the outcome of checking an import against a list.
+type importVerdict struct {
+	allowed    bool
+	suggestion string
+	// rule is the allow or deny entry that decided the outcome, if any.
+	rule string
+	// allowEntry and denyEntry are the allow and deny entries, as configured, that
+	// matched the import whether or not they decided the outcome.
+	allowEntry string
+	denyEntry  string
+	metadata   Metadata
+	// kind describes which import style was not allowed, empty for anything else.
+	kind string
+	// related are the positions of what makes up the violation, like the imports
+	// counted against a budget.
+	related []token.Pos
 }
 
 func (l *List) compile() (*list, error) {
 	if l == nil {
 		return nil, nil
 	}
-	li := &list{}
+	li := &list{
+		metadata: Metadata{
+			Owner:  strings.TrimSpace(l.Owner),
+			URL:    strings.TrimSpace(l.URL),
+			Reason: strings.TrimSpace(l.Reason),
+		},
+	}
 	var errs utils.MultiError
 	var err error
 
@@ -57,6 +304,15 @@ func (l *List) compile() (*list, error) {
 		errs = append(errs, fmt.Errorf("%s is not a known list mode", l.ListMode))
 	}
 
+	// Determine Severity
+	switch severity := strings.ToLower(strings.TrimSpace(l.Severity)); severity {
+	case "":
+	case severityError, severityWarning, severityInfo:
+		li.severity = severity
+	default:
+		errs = append(errs, fmt.Errorf("%s is not a known severity", l.Severity))
+	}
+
 	// Compile Files
 	for _, f := range l.Files {
 		var negate bool
@@ -64,6 +320,15 @@ func (l *List) compile() (*list, error) {
 			negate = true
 			f = f[1:]
 		}
+		// Generated files are found by their content so can't be expanded into a glob
+		if f == generatedVariable {
+			if negate {
+				li.negGenerated = true
+				continue
+			}
+			li.generated = true
+			continue
+		}
 		// Expand File if needed
 		fs, err := utils.ExpandSlice([]string{f}, utils.PathExpandable)
 		if err != nil {
@@ -83,29 +348,117 @@ func (l *List) compile() (*list, error) {
 		}
 	}
 
+	if l.Root != "" {
+		root, err := filepath.Abs(l.Root)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("root %s could not be resolved: %w", l.Root, err))
+		}
+		li.root = filepath.ToSlash(root)
+	}
+
+	// Compile Packages
+	for _, p := range l.Packages {
+		var negate bool
+		if len(p) > 0 && p[0] == '!' {
+			negate = true
+			p = p[1:]
+		}
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s could not be compiled: %w", p, err))
+			continue
+		}
+		if negate {
+			li.negPackages = append(li.negPackages, g)
+			continue
+		}
+		li.packages = append(li.packages, g)
+	}
+
+	// Compile Build Tags
+	for _, bt := range l.BuildTags {
+		var negate bool
+		if len(bt) > 0 && bt[0] == '!' {
+			negate = true
+			bt = bt[1:]
+		}
+		tags := strings.Split(bt, ",")
+		for i := range tags {
+			tags[i] = strings.TrimSpace(tags[i])
+			if tags[i] == "" {
+				errs = append(errs, fmt.Errorf("%s is not a valid set of build tags", bt))
+				break
+			}
+		}
+		if negate {
+			li.negBuildTags = append(li.negBuildTags, tags)
+			continue
+		}
+		li.buildTags = append(li.buildTags, tags)
+	}
+
 	if len(l.Allow) > 0 {
-		// Expand Allow
+		// Expand Allow, Remembering Which Entries Came From Variables
+		li.allowVariables = packageVariables(l.Allow)
 		l.Allow, err = utils.ExpandSlice(l.Allow, utils.PackageExpandable)
 		if err != nil {
 			errs = append(errs, err)
 		}
 
-		// Sort Allow
-		li.allow = make([]string, len(l.Allow))
-		copy(li.allow, l.Allow)
+		// Split Version Constraints Off And Sort Allow
+		li.allow = make([]string, 0, len(l.Allow))
+		for _, a := range l.Allow {
+			pkg, vc, err := splitVersion(a)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if vc != nil {
+				if li.allowVersions == nil {
+					li.allowVersions = make(map[string]*versionConstraint)
+				}
+				if _, ok := li.allowVersions[pkg]; ok {
+					errs = append(errs, fmt.Errorf("%s has more than one version constraint", pkg))
+				}
+				li.allowVersions[pkg] = vc
+			}
+			li.allow = append(li.allow, pkg)
+		}
 		sort.Strings(li.allow)
 	}
 
 	if l.Deny != nil {
-		// Expand Deny Map (to keep suggestions)
+		// Expand Deny Map (to keep suggestions), Remembering Which Entries Came From Variables
+		keys := make([]string, 0, len(l.Deny))
+		for key := range l.Deny {
+			keys = append(keys, key)
+		}
+		li.denyVariables = packageVariables(keys)
 		err = utils.ExpandMap(l.Deny, utils.PackageExpandable)
 		if err != nil {
 			errs = append(errs, err)
 		}
 
-		// Split Deny Into Package Slice
+		// Split Deny Into Package Slice, Keeping The Version Constraints Apart
 		li.deny = make([]string, 0, len(l.Deny))
-		for pkg := range l.Deny {
+		denyKeys := make(map[string]string, len(l.Deny))
+		for key := range l.Deny {
+			pkg, vc, err := splitVersion(key)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if _, ok := denyKeys[pkg]; ok {
+				errs = append(errs, fmt.Errorf("%s has more than one version constraint", pkg))
+				continue
+			}
+			if vc != nil {
+				if li.denyVersions == nil {
+					li.denyVersions = make(map[string]*versionConstraint)
+				}
+				li.denyVersions[pkg] = vc
+			}
+			denyKeys[pkg] = key
 			li.deny = append(li.deny, pkg)
 		}
 
@@ -115,13 +468,237 @@ func (l *List) compile() (*list, error) {
 		// Populate Suggestions to match the Deny order
 		li.suggestions = make([]string, 0, len(li.deny))
 		for _, dp := range li.deny {
-			li.suggestions = append(li.suggestions, strings.TrimSpace(l.Deny[dp]))
+			li.suggestions = append(li.suggestions, strings.TrimSpace(l.Deny[denyKeys[dp]]))
+		}
+	}
+
+	if len(l.DenyMetadata) > 0 {
+		// Expand Deny Metadata the same way as Deny so keys line up
+		err = utils.ExpandMap(l.DenyMetadata, utils.PackageExpandable)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		// Populate Metadata to match the Deny order
+		li.denyMetadata = make([]Metadata, len(li.deny))
+		for key, md := range l.DenyMetadata {
+			// Metadata may be keyed with or without the version constraint of the entry
+			pkg, _, _ := strings.Cut(key, "@")
+			idx := sort.SearchStrings(li.deny, pkg)
+			if idx == len(li.deny) || li.deny[idx] != pkg {
+				errs = append(errs, fmt.Errorf("metadata for %s has no matching deny entry", key))
+				continue
+			}
+			if md != nil {
+				li.denyMetadata[idx] = Metadata{
+					Owner:  strings.TrimSpace(md.Owner),
+					URL:    strings.TrimSpace(md.URL),
+					Reason: strings.TrimSpace(md.Reason),
+				}
+			}
+		}
+	}
+
+	if l.DenySymbols != nil {
+		// Split Deny Symbols Into Symbol Slice
+		li.denySymbols = make([]string, 0, len(l.DenySymbols))
+		for sym := range l.DenySymbols {
+			if dot := strings.LastIndexByte(sym, '.'); dot <= strings.LastIndexByte(sym, '/')+1 || dot == len(sym)-1 {
+				errs = append(errs, fmt.Errorf("%s is not a symbol, expected the form package.Name", sym))
+				continue
+			}
+			li.denySymbols = append(li.denySymbols, sym)
+		}
+
+		// Sort Deny Symbols
+		sort.Strings(li.denySymbols)
+
+		// Populate Suggestions to match the Deny Symbols order
+		li.symbolSuggestions = make([]string, 0, len(li.denySymbols))
+		for _, ds := range li.denySymbols {
+			li.symbolSuggestions = append(li.symbolSuggestions, strings.TrimSpace(l.DenySymbols[ds]))
+		}
+	}
+
+	li.denyDotImports = l.DenyDotImports
+	li.restrictBlank = l.DenyBlankImports || len(l.AllowBlankImports) > 0
+	if len(l.AllowBlankImports) > 0 {
+		// Expand Allow Blank Imports
+		l.AllowBlankImports, err = utils.ExpandSlice(l.AllowBlankImports, utils.PackageExpandable)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		// Sort Allow Blank Imports
+		li.allowBlank = make([]string, len(l.AllowBlankImports))
+		copy(li.allowBlank, l.AllowBlankImports)
+		sort.Strings(li.allowBlank)
+	}
+
+	// Compile Deny Dirs
+	for _, d := range l.DenyDirs {
+		d = strings.Trim(d, "/")
+		g, err := glob.Compile(d, '/')
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s could not be compiled: %w", d, err))
+			continue
+		}
+		li.denyDirs = append(li.denyDirs, &dirRule{pattern: d, glob: g})
+	}
+
+	// Compile Visibility
+	for pattern, importers := range l.Visibility {
+		g, err := compileTreeGlob(pattern)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s could not be compiled: %w", pattern, err))
+			continue
+		}
+		rule := &visibilityRule{pattern: pattern, glob: g, importers: importers}
+		for _, imp := range importers {
+			ig, err := compileTreeGlob(imp)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s could not be compiled: %w", imp, err))
+				continue
+			}
+			rule.globs = append(rule.globs, ig)
+		}
+		li.visibility = append(li.visibility, rule)
+	}
+	sort.Slice(li.visibility, func(i, j int) bool {
+		return li.visibility[i].pattern < li.visibility[j].pattern
+	})
+
+	// Compile Module Order
+	for _, m := range l.ModuleOrder {
+		g, err := glob.Compile(m, '/')
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s could not be compiled: %w", m, err))
+			continue
+		}
+		li.moduleOrder = append(li.moduleOrder, &dirRule{pattern: m, glob: g})
+	}
+	li.denyModuleCycles = l.DenyModuleCycles
+
+	// Compile Licenses
+	for _, id := range l.AllowLicenses {
+		g, err := glob.Compile(id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s could not be compiled: %w", id, err))
+			continue
+		}
+		li.allowLicenses = append(li.allowLicenses, &licenseRule{pattern: id, glob: g})
+	}
+	for id, suggestion := range l.DenyLicenses {
+		g, err := glob.Compile(id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s could not be compiled: %w", id, err))
+			continue
+		}
+		li.denyLicenses = append(li.denyLicenses, &licenseRule{pattern: id, glob: g, suggestion: strings.TrimSpace(suggestion)})
+	}
+	sort.Slice(li.denyLicenses, func(i, j int) bool {
+		return li.denyLicenses[i].pattern < li.denyLicenses[j].pattern
+	})
+
+	li.denyDeprecated = l.DenyDeprecated
+	li.denyRetracted = l.DenyRetracted
+	li.denyLocalReplace = l.DenyLocalReplace
+
+	if len(l.RequireReplace) > 0 {
+		li.requireReplace = make(map[string]string, len(l.RequireReplace))
+		for mod, fork := range l.RequireReplace {
+			li.requireReplace[strings.TrimSpace(mod)] = strings.TrimSpace(fork)
+		}
+	}
+
+	if len(l.VendoredOnly) > 0 {
+		// Expand Vendored Only
+		l.VendoredOnly, err = utils.ExpandSlice(l.VendoredOnly, utils.PackageExpandable)
+		if err != nil {
+			errs = append(errs, err)
+		}
+
+		// Sort Vendored Only
+		li.vendoredOnly = make([]string, len(l.VendoredOnly))
+		copy(li.vendoredOnly, l.VendoredOnly)
+		sort.Strings(li.vendoredOnly)
+	}
+
+	if l.VulnDB != "" {
+		dir, err := filepath.Abs(l.VulnDB)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("vulnDB %s could not be resolved: %w", l.VulnDB, err))
+		}
+		li.vulnDB = dir
+	}
+
+	if l.MaxImports < 0 || l.MaxExternalModules < 0 {
+		errs = append(errs, errors.New("maxImports and maxExternalModules can not be negative"))
+	}
+	li.maxImports = l.MaxImports
+	li.maxModules = l.MaxExternalModules
+
+	if l.DenyTestOnly {
+		// Test files are whatever $test matches
+		fs, err := utils.ExpandSlice([]string{"$test"}, utils.PathExpandable)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, exp := range fs {
+			g, err := glob.Compile(exp, '/')
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s could not be compiled: %w", exp, err))
+				continue
+			}
+			li.testFiles = append(li.testFiles, g)
+		}
+
+		// Test only packages are whatever $testonly matches
+		li.testOnly, err = utils.ExpandSlice([]string{"$testonly"}, utils.PackageExpandable)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		sort.Strings(li.testOnly)
+	}
+
+	if len(l.Aliases) > 0 {
+		li.aliases = make(map[string]*aliasRule, len(l.Aliases))
+		for pkg, aliases := range l.Aliases {
+			rule := &aliasRule{}
+			for _, a := range aliases {
+				if len(a) > 0 && a[0] == '!' {
+					rule.denied = append(rule.denied, a[1:])
+					continue
+				}
+				rule.allowed = append(rule.allowed, a)
+			}
+			if len(rule.allowed) == 0 && len(rule.denied) == 0 {
+				errs = append(errs, fmt.Errorf("aliases for %s must not be empty", pkg))
+				continue
+			}
+			li.aliases[pkg] = rule
+		}
+	}
+
+	if l.Message != "" {
+		tmpl, err := template.New("message").Parse(l.Message)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("message could not be compiled: %w", err))
+		} else if err = tmpl.Execute(io.Discard, &messageData{}); err != nil {
+			errs = append(errs, fmt.Errorf("message could not be executed: %w", err))
+		} else {
+			li.message = tmpl
 		}
 	}
 
 	// Populate the type of this list
-	if len(li.allow) == 0 && len(li.deny) == 0 {
-		errs = append(errs, errors.New("must have an Allow and/or Deny package list"))
+	if len(li.allow) == 0 && len(li.deny) == 0 && len(li.denySymbols) == 0 &&
+		!li.denyDotImports && !li.restrictBlank && len(li.aliases) == 0 && len(li.testOnly) == 0 &&
+		len(li.denyDirs) == 0 && len(li.visibility) == 0 && len(li.moduleOrder) == 0 && !li.denyModuleCycles &&
+		li.maxImports <= 0 && li.maxModules <= 0 && len(li.allowLicenses) == 0 && len(li.denyLicenses) == 0 &&
+		!li.denyDeprecated && !li.denyRetracted && li.vulnDB == "" &&
+		!li.denyLocalReplace && len(li.requireReplace) == 0 && len(li.vendoredOnly) == 0 {
+		errs = append(errs, errors.New("must have at least one rule, such as an Allow or Deny package list, DenySymbols, import styles, DenyDirs, Visibility, module rules, licenses or import budgets"))
 	}
 
 	if len(errs) > 0 {
@@ -130,36 +707,535 @@ func (l *List) compile() (*list, error) {
 	return li, nil
 }
 
-func (l *list) fileMatch(fileName string) bool {
-	inAllowed := len(l.files) == 0 || strInGlobList(fileName, l.files)
-	inDenied := strInGlobList(fileName, l.negFiles)
+// fileMatch matches the globs against any of the names a file is known by.
+func (l *list) fileMatch(fileNames ...string) bool {
+	inAllowed := len(l.files) == 0 && !l.generated
+	inDenied := false
+	for _, fileName := range fileNames {
+		inAllowed = inAllowed || strInGlobList(fileName, l.files)
+		inDenied = inDenied || strInGlobList(fileName, l.negFiles)
+	}
 	return inAllowed && !inDenied
 }
 
-func (l *list) importAllowed(imp string) (bool, string) {
+func (l *list) packageMatch(pkgPath string) bool {
+	inAllowed := len(l.packages) == 0 || strInGlobList(pkgPath, l.packages)
+	inDenied := strInGlobList(pkgPath, l.negPackages)
+	return inAllowed && !inDenied
+}
+
+func (l *list) buildTagMatch(expr constraint.Expr) bool {
+	inAllowed := len(l.buildTags) == 0
+	for _, tags := range l.buildTags {
+		inAllowed = inAllowed || constrainedTo(expr, tags)
+	}
+	for _, tags := range l.negBuildTags {
+		if constrainedTo(expr, tags) {
+			return false
+		}
+	}
+	return inAllowed
+}
+
+// match reports whether the list applies to the source file.
+func (l *list) match(src *source) bool {
+	root := l.root
+	if root == "" {
+		root = src.moduleRoot
+	}
+	fileMatch := l.fileMatch(src.fileName)
+	if rel, ok := relativeTo(src.fileName, root); ok {
+		fileMatch = l.fileMatch(src.fileName, rel)
+	}
+	if src.generated {
+		fileMatch = (fileMatch || l.generated) && !l.negGenerated
+	}
+	return fileMatch && l.packageMatch(src.pkgPath) && l.buildTagMatch(src.constraint)
+}
+
+// relativeTo returns the slash separated fileName relative to root if it is inside of it.
+func relativeTo(fileName, root string) (string, bool) {
+	if root == "" {
+		return "", false
+	}
+	rel := strings.TrimPrefix(fileName, strings.TrimSuffix(root, "/")+"/")
+	return rel, rel != fileName
+}
+
+func (l *list) importAllowed(imp, version string) *importVerdict {
 	inAllowed, aIdx := strInPrefixList(imp, l.allow)
 	inDenied, dIdx := strInPrefixList(imp, l.deny)
-	var allowed bool
+	// Entries with a version constraint only match versions within it. An unknown
+	// version still matches an allow entry but never a deny entry.
+	var outdated *versionConstraint
+	if inAllowed {
+		if vc := l.allowVersions[l.allow[aIdx]]; vc != nil && version != "" && !vc.satisfiedBy(version) {
+			inAllowed = false
+			outdated = vc
+		}
+	}
+	if inDenied {
+		if vc := l.denyVersions[l.deny[dIdx]]; vc != nil && (version == "" || !vc.satisfiedBy(version)) {
+			inDenied = false
+		}
+	}
+	v := &importVerdict{metadata: l.metadata}
+	if inAllowed {
+		v.allowEntry = configEntry(l.allow[aIdx], l.allowVariables, l.allowVersions)
+	}
+	if inDenied {
+		v.denyEntry = configEntry(l.deny[dIdx], l.denyVariables, l.denyVersions)
+	}
 	switch l.listMode {
 	case listModeOriginal:
-		inAllowed = len(l.allow) == 0 || inAllowed
-		allowed = inAllowed && !inDenied
+		v.allowed = (len(l.allow) == 0 || inAllowed) && !inDenied
 	case listModeStrict:
-		allowed = inAllowed && (!inDenied || len(l.allow[aIdx]) > len(l.deny[dIdx]))
+		v.allowed = inAllowed && (!inDenied || len(l.allow[aIdx]) > len(l.deny[dIdx]))
 	case listModeLax:
-		allowed = !inDenied || (inAllowed && len(l.allow[aIdx]) > len(l.deny[dIdx]))
+		v.allowed = !inDenied || (inAllowed && len(l.allow[aIdx]) > len(l.deny[dIdx]))
+	default:
+		v.allowed = false
+	}
+	switch {
+	case v.allowed && inAllowed:
+		v.rule = l.allow[aIdx]
+	case !v.allowed && inDenied && dIdx != -1:
+		v.rule = l.deny[dIdx]
+		if vc := l.denyVersions[v.rule]; vc != nil {
+			v.rule += "@" + vc.raw
+		}
+		v.suggestion = l.suggestions[dIdx]
+		if l.denyMetadata != nil {
+			v.metadata = l.metadata.merge(l.denyMetadata[dIdx])
+		}
+	case !v.allowed && outdated != nil:
+		v.rule = l.allow[aIdx] + "@" + outdated.raw
+		v.suggestion = fmt.Sprintf("version %s is not %s", version, outdated.raw)
+	}
+	return v
+}
+
+// packageVariables maps the packages the package variables among the entries expand to
+// back to the variable. Packages that are entries themselves are left out, as are
+// variables that fail to expand, which expanding the entries reports.
+func packageVariables(entries []string) map[string]string {
+	var vars map[string]string
+	for _, e := range entries {
+		exp, ok := utils.PackageExpandable[e]
+		if !ok {
+			continue
+		}
+		pkgs, err := exp.Expand()
+		if err != nil {
+			continue
+		}
+		if vars == nil {
+			vars = make(map[string]string, len(pkgs))
+		}
+		for _, pkg := range pkgs {
+			if _, ok := vars[pkg]; !ok {
+				vars[pkg] = e
+			}
+		}
+	}
+	for _, e := range entries {
+		pkg, _, _ := strings.Cut(e, "@")
+		delete(vars, pkg)
+	}
+	return vars
+}
+
+// configEntry is the entry as it was configured that the compiled entry pkg came from.
+func configEntry(pkg string, vars map[string]string, versions map[string]*versionConstraint) string {
+	if v, ok := vars[pkg]; ok {
+		return v
+	}
+	if vc := versions[pkg]; vc != nil {
+		return pkg + "@" + vc.raw
+	}
+	return pkg
+}
+
+// symbolAllowed checks a package level symbol against the deny symbols of the list.
+// Symbols are only ever denied explicitly, though in Strict and Lax modes a longer
+// allow entry takes precedence just like it does for packages.
+func (l *list) symbolAllowed(sym string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	inDenied, dIdx := strInPrefixList(sym, l.denySymbols)
+	if !inDenied {
+		return v
+	}
+	inAllowed, aIdx := strInPrefixList(sym, l.allow)
+	if l.listMode != listModeOriginal && inAllowed && len(l.allow[aIdx]) > len(l.denySymbols[dIdx]) {
+		v.rule = l.allow[aIdx]
+		return v
+	}
+	v.allowed = false
+	v.rule = l.denySymbols[dIdx]
+	v.suggestion = l.symbolSuggestions[dIdx]
+	return v
+}
+
+// styleAllowed checks how a package is imported, with name being the alias of
+// the import or empty when it has none, and pkgName the name the package declares,
+// which is what an import without an alias is used as.
+func (l *list) styleAllowed(imp, name, pkgName string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	switch name {
+	case ".":
+		if l.denyDotImports {
+			v.allowed = false
+			v.kind = "dot"
+		}
+	case "_":
+		if !l.restrictBlank {
+			break
+		}
+		if inAllowed, aIdx := strInPrefixList(imp, l.allowBlank); inAllowed {
+			v.rule = l.allowBlank[aIdx]
+			break
+		}
+		v.allowed = false
+		v.kind = "blank"
+	default:
+		rule := l.aliases[imp]
+		if rule == nil {
+			break
+		}
+		used := name
+		if used == "" {
+			used = pkgName
+		}
+		if strInList(used, rule.denied) || (len(rule.allowed) > 0 && !strInList(used, rule.allowed)) {
+			v.allowed = false
+			v.kind = "aliased"
+			if name == "" {
+				v.kind = "unaliased"
+			}
+			switch {
+			case len(rule.allowed) > 0:
+				v.suggestion = fmt.Sprintf("alias it as %s", strings.Join(rule.allowed, " or "))
+			case name == "":
+				v.suggestion = fmt.Sprintf("alias it as something other than %s", used)
+			}
+		}
+	}
+	return v
+}
+
+// dirAllowed checks the directory a package is in, relative to the root of its module.
+func (l *list) dirAllowed(dir string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	for _, d := range l.denyDirs {
+		if d.glob.Match(dir) {
+			v.allowed = false
+			v.rule = d.pattern
+			v.suggestion = fmt.Sprintf("it is located in %s", dir)
+			return v
+		}
+	}
+	return v
+}
+
+// visibilityAllowed checks that the importing package may see the imported one.
+// Both are given by all the names they are known by, see packageNames.
+// A package is always visible to the packages matching its own pattern.
+func (l *list) visibilityAllowed(imp, importer []string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	for _, rule := range l.visibility {
+		if !strsInGlob(imp, rule.glob) || strsInGlob(importer, rule.glob) {
+			continue
+		}
+		visible := false
+		for _, g := range rule.globs {
+			if strsInGlob(importer, g) {
+				visible = true
+				break
+			}
+		}
+		if !visible {
+			v.allowed = false
+			v.rule = rule.pattern
+			v.suggestion = fmt.Sprintf("it is only visible to %s", strings.Join(rule.importers, ", "))
+			return v
+		}
+	}
+	return v
+}
+
+// compileTreeGlob compiles a package glob where a trailing `/**` also matches the
+// directory itself, so `services/billing/**` includes `services/billing`.
+func compileTreeGlob(pattern string) (glob.Glob, error) {
+	if dir, found := strings.CutSuffix(pattern, "/**"); found {
+		pattern = dir + "{,/**}"
+	}
+	return glob.Compile(pattern, '/')
+}
+
+// packageNames returns the package path along with the path relative to the module
+// when the package is part of it, so globs can be written either way.
+func packageNames(pkgPath, modulePath string) []string {
+	if rel := strings.TrimPrefix(pkgPath, modulePath+"/"); modulePath != "" && rel != pkgPath {
+		return []string{pkgPath, rel}
+	}
+	return []string{pkgPath}
+}
+
+// moduleAllowed checks an import that makes module from depend on module to
+// against the module order and, with the graph of the package, for cycles.
+func (l *list) moduleAllowed(graph *moduleGraphFact, from, to string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if from == "" || to == "" || from == to {
+		return v
+	}
+	if l.denyModuleCycles && graph != nil {
+		if p := graph.path(to, from); p != nil {
+			v.allowed = false
+			v.suggestion = "it creates the module cycle " + formatModulePath(append([]string{from}, p...))
+			return v
+		}
+	}
+	fromIdx, toIdx := l.moduleLayer(from), l.moduleLayer(to)
+	if fromIdx != -1 && toIdx > fromIdx {
+		v.allowed = false
+		v.rule = l.moduleOrder[toIdx].pattern
+		v.suggestion = fmt.Sprintf("module %s comes before %s in the module order", from, to)
+	}
+	return v
+}
+
+// moduleLayer returns the index of the first module order entry matching the module, -1 if none do.
+func (l *list) moduleLayer(mod string) int {
+	for i, m := range l.moduleOrder {
+		if m.glob.Match(mod) {
+			return i
+		}
+	}
+	return -1
+}
+
+// budgetAllowed checks the distinct imports or modules of a package against a limit.
+// The suggestion lists all of them when there are too many.
+func (l *list) budgetAllowed(items []string, limit int) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if limit <= 0 || len(items) <= limit {
+		return v
+	}
+	v.allowed = false
+	v.suggestion = strings.Join(items, ", ")
+	return v
+}
+
+// usesLicenses reports whether the list checks the licenses of imported modules.
+func (l *list) usesLicenses() bool {
+	return len(l.allowLicenses) > 0 || len(l.denyLicenses) > 0
+}
+
+// licenseAllowed checks the SPDX id of the license of an imported module, empty
+// when no license was found. Denied licenses win over allowed ones and once any
+// license is allowed every other one, and a missing license, is denied.
+func (l *list) licenseAllowed(license string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if license != "" {
+		for _, r := range l.denyLicenses {
+			if r.glob.Match(license) {
+				v.allowed = false
+				v.rule = r.pattern
+				v.suggestion = r.suggestion
+				if v.suggestion == "" {
+					v.suggestion = "it is licensed under " + license
+				}
+				return v
+			}
+		}
+	}
+	if len(l.allowLicenses) == 0 {
+		return v
+	}
+	for _, r := range l.allowLicenses {
+		if license != "" && r.glob.Match(license) {
+			v.rule = r.pattern
+			return v
+		}
+	}
+	v.allowed = false
+	if license == "" {
+		v.suggestion = "no known license was found"
+	} else {
+		v.suggestion = "it is licensed under " + license
+	}
+	return v
+}
+
+// deprecatedAllowed checks the deprecation messages of an imported package and its module,
+// empty when they are not deprecated.
+func (l *list) deprecatedAllowed(pkgMsg, modMsg string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if !l.denyDeprecated {
+		return v
+	}
+	switch {
+	case pkgMsg != "":
+		v.suggestion = pkgMsg
+	case modMsg != "":
+		v.suggestion = "the module is deprecated: " + modMsg
 	default:
-		allowed = false
+		return v
+	}
+	v.allowed = false
+	v.kind = "deprecated"
+	return v
+}
+
+// usesReplacements reports whether the list checks what go.mod replaces imported
+// modules with, which its allow and deny entries do as well as its replace rules.
+func (l *list) usesReplacements() bool {
+	return len(l.allow) > 0 || len(l.deny) > 0 || l.denyLocalReplace || len(l.requireReplace) > 0
+}
+
+// replaceAllowed checks how go.mod replaces an imported module, rep is nil when it isn't.
+func (l *list) replaceAllowed(mod string, rep *modfile.Replace) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if fork, ok := l.requireReplace[mod]; ok && (rep == nil || rep.New.Path != fork) {
+		v.allowed = false
+		v.rule = mod
+		v.suggestion = "replace it with " + fork
+		return v
 	}
-	sugg := ""
-	if !allowed && inDenied && dIdx != -1 {
-		sugg = l.suggestions[dIdx]
+	if l.denyLocalReplace && isLocalReplace(rep) {
+		v.allowed = false
+		v.kind = "replaced"
+		v.suggestion = "it is replaced with the local directory " + rep.New.Path
 	}
-	return allowed, sugg
+	return v
+}
+
+// vendoredAllowed checks that packages which may only be used vendored are.
+func (l *list) vendoredAllowed(imp string, vendored bool) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if vendored {
+		return v
+	}
+	inVendoredOnly, idx := strInPrefixList(imp, l.vendoredOnly)
+	if !inVendoredOnly {
+		return v
+	}
+	v.allowed = false
+	v.rule = l.vendoredOnly[idx]
+	v.suggestion = "it must be vendored, run go mod vendor"
+	return v
+}
+
+// retractedAllowed checks the retractions of an imported module against its required version.
+func (l *list) retractedAllowed(retracts []*modfile.Retract, version string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if !l.denyRetracted || version == "" {
+		return v
+	}
+	r := retraction(retracts, version)
+	if r == nil {
+		return v
+	}
+	v.allowed = false
+	v.kind = "retracted"
+	v.rule = r.Low
+	if r.High != r.Low {
+		v.rule = "[" + r.Low + ", " + r.High + "]"
+	}
+	v.suggestion = retractedMessage(version, r)
+	return v
+}
+
+// vulnAllowed checks the known vulnerabilities of an imported package of a module at a version.
+func (l *list) vulnAllowed(vulns []*osvEntry, mod, version string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if len(vulns) == 0 {
+		return v
+	}
+	ids := make([]string, 0, len(vulns))
+	descs := make([]string, 0, len(vulns))
+	for _, vuln := range vulns {
+		ids = append(ids, vuln.ID)
+		desc := vuln.ID
+		if vuln.Summary != "" {
+			desc += ": " + vuln.Summary
+		}
+		if fixed := vuln.fixedIn(mod, version); fixed != "" {
+			desc += " (fixed in " + fixed + ")"
+		}
+		descs = append(descs, desc)
+	}
+	v.allowed = false
+	v.kind = "vulnerable"
+	v.rule = strings.Join(ids, ", ")
+	v.suggestion = strings.Join(descs, "; ")
+	return v
+}
+
+// isTestOnly reports whether a package is only meant for tests, which lets test
+// helper packages and the test main go generates import other test only packages.
+func (l *list) isTestOnly(pkg string) bool {
+	inTestOnly, _ := strInPrefixList(pkg, l.testOnly)
+	return inTestOnly || utils.IsTestOnlyName(pkg) || strings.HasSuffix(pkg, ".test")
+}
+
+// testOnlyAllowed checks that packages only meant for tests are only imported from test
+// files, or by packages only meant for tests themselves.
+func (l *list) testOnlyAllowed(imp, importer, fileName string) *importVerdict {
+	v := &importVerdict{allowed: true, metadata: l.metadata}
+	if len(l.testOnly) == 0 || strInGlobList(fileName, l.testFiles) || l.isTestOnly(importer) {
+		return v
+	}
+	inTestOnly, idx := strInPrefixList(imp, l.testOnly)
+	if !inTestOnly && !utils.IsTestOnlyName(imp) {
+		return v
+	}
+	v.allowed = false
+	v.kind = "test-only"
+	if inTestOnly {
+		v.rule = l.testOnly[idx]
+	}
+	v.suggestion = "only import it from _test.go files"
+	return v
+}
+
+// formatMessage renders the diagnostic message for an import this list does not allow.
+func (l *list) formatMessage(data *messageData) (string, error) {
+	tmpl := l.message
+	switch {
+	case tmpl != nil:
+	case data.Symbol != "":
+		tmpl = defaultSymbolMessage
+	case data.Package != "":
+		tmpl = defaultBudgetMessage
+	default:
+		tmpl = defaultMessage
+	}
+	b := strings.Builder{}
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("could not render message for list '%s': %w", l.name, err)
+	}
+	return b.String(), nil
 }
 
 type LinterSettings map[string]*List
 
+// source describes the file being checked, which is what lists are selected by.
+type source struct {
+	// fileName is the absolute path of the file using '/' as the separator.
+	fileName string
+	pkgPath  string
+	// moduleRoot is the directory of the go.mod file the file belongs to using '/' as the separator.
+	moduleRoot string
+	modulePath string
+	// constraint is the build constraint of the file, nil when it has none.
+	constraint constraint.Expr
+	generated  bool
+}
+
+// generatedVariable is the file variable matching generated files.
+const generatedVariable = "$generated"
+
 type linterSettings []*list
 
 func (l LinterSettings) compile() (linterSettings, error) {
@@ -202,10 +1278,10 @@ func (l LinterSettings) compile() (linterSettings, error) {
 	return li, nil
 }
 
-func (ls linterSettings) whichLists(fileName string) []*list {
+func (ls linterSettings) whichLists(src *source) []*list {
 	var matches []*list
 	for _, l := range ls {
-		if l.fileMatch(fileName) {
+		if l.match(src) {
 			matches = append(matches, l)
 		}
 	}
@@ -221,6 +1297,24 @@ func strInGlobList(str string, globList []glob.Glob) bool {
 	return false
 }
 
+func strsInGlob(strs []string, g glob.Glob) bool {
+	for _, str := range strs {
+		if g.Match(str) {
+			return true
+		}
+	}
+	return false
+}
+
+func strInList(str string, list []string) bool {
+	for _, s := range list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
 func strInPrefixList(str string, prefixList []string) (bool, int) {
 	// Idx represents where in the prefix slice the passed in string would go
 	// when sorted. -1 Just means that it would be at the very front of the slice.
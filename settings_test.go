@@ -2,14 +2,18 @@ package depguard
 
 import (
 	"errors"
+	"go/build/constraint"
 	"sort"
 	"strconv"
 	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/OpenPeeDeeP/depguard/v2/internal/utils"
 	"github.com/gobwas/glob"
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
 )
 
 type listCompileScenario struct {
@@ -33,7 +37,7 @@ var (
 			list: &List{
 				Files: []string{"**/*.go"},
 			},
-			expErr: errors.New("must have an Allow and/or Deny package list"),
+			expErr: errors.New("must have at least one rule, such as an Allow or Deny package list, DenySymbols, import styles, DenyDirs, Visibility, module rules, licenses or import budgets"),
 		},
 		{
 			name: "No Files",
@@ -91,6 +95,90 @@ var (
 				allow: []string{"os"},
 			},
 		},
+		{
+			name: "Normal and Negatable Packages",
+			list: &List{
+				Packages: []string{"example.com/**", "!example.com/**/internal/**"},
+				Allow:    []string{"os"},
+			},
+			exp: &list{
+				packages: []glob.Glob{
+					glob.MustCompile("example.com/**", '/'),
+				},
+				negPackages: []glob.Glob{
+					glob.MustCompile("example.com/**/internal/**", '/'),
+				},
+				allow: []string{"os"},
+			},
+		},
+		{
+			name: "Failure to Compile Package Glob",
+			list: &List{
+				Packages: []string{"[a-]/foo"},
+				Allow:    []string{"os"},
+			},
+			expErr: errors.New("[a-]/foo could not be compiled"),
+		},
+		{
+			name: "Root",
+			list: &List{
+				Root:  "/some/root/",
+				Files: []string{"internal/**/*.go"},
+				Allow: []string{"os"},
+			},
+			exp: &list{
+				root: "/some/root",
+				files: []glob.Glob{
+					glob.MustCompile("internal/**/*.go", '/'),
+				},
+				allow: []string{"os"},
+			},
+		},
+		{
+			name: "Build Tags",
+			list: &List{
+				BuildTags: []string{"windows", "linux, amd64", "!plan9"},
+				Allow:     []string{"syscall"},
+			},
+			exp: &list{
+				buildTags:    [][]string{{"windows"}, {"linux", "amd64"}},
+				negBuildTags: [][]string{{"plan9"}},
+				allow:        []string{"syscall"},
+			},
+		},
+		{
+			name: "Empty Build Tag",
+			list: &List{
+				BuildTags: []string{"linux,"},
+				Allow:     []string{"syscall"},
+			},
+			expErr: errors.New("linux, is not a valid set of build tags"),
+		},
+		{
+			name: "Generated Files",
+			list: &List{
+				Files: []string{"$generated"},
+				Allow: []string{"os"},
+			},
+			exp: &list{
+				generated: true,
+				allow:     []string{"os"},
+			},
+		},
+		{
+			name: "Negate Generated Files",
+			list: &List{
+				Files: []string{"$all", "!$generated"},
+				Allow: []string{"os"},
+			},
+			exp: &list{
+				files: []glob.Glob{
+					glob.MustCompile("**/*.go", '/'),
+				},
+				negGenerated: true,
+				allow:        []string{"os"},
+			},
+		},
 		{
 			name: "Failure to Compile File Glob",
 			list: &List{
@@ -104,7 +192,8 @@ var (
 				Allow: []string{"$gostd"},
 			},
 			exp: &list{
-				allow: []string{"FIND ME", "FIND ME TOO"},
+				allow:          []string{"FIND ME", "FIND ME TOO"},
+				allowVariables: map[string]string{"FIND ME": "$gostd", "FIND ME TOO": "$gostd"},
 			},
 		},
 		{
@@ -113,8 +202,9 @@ var (
 				Deny: map[string]string{"$gostd": "Don't use standard"},
 			},
 			exp: &list{
-				deny:        []string{"FIND ME", "FIND ME TOO"},
-				suggestions: []string{"Don't use standard", "Don't use standard"},
+				deny:          []string{"FIND ME", "FIND ME TOO"},
+				denyVariables: map[string]string{"FIND ME": "$gostd", "FIND ME TOO": "$gostd"},
+				suggestions:   []string{"Don't use standard", "Don't use standard"},
 			},
 		},
 		{
@@ -222,6 +312,302 @@ var (
 				suggestions: []string{"Don't use Reflect"},
 			},
 		},
+		{
+			name: "Custom Message",
+			list: &List{
+				Allow:   []string{"os"},
+				Message: "{{.Import}} is banned by {{.List}}",
+			},
+			exp: &list{
+				allow:   []string{"os"},
+				message: template.Must(template.New("message").Parse("{{.Import}} is banned by {{.List}}")),
+			},
+		},
+		{
+			name: "Failure to Compile Message",
+			list: &List{
+				Allow:   []string{"os"},
+				Message: "{{.Import",
+			},
+			expErr: errors.New("message could not be compiled"),
+		},
+		{
+			name: "Message With Unknown Field",
+			list: &List{
+				Allow:   []string{"os"},
+				Message: "{{.Nope}}",
+			},
+			expErr: errors.New("message could not be executed"),
+		},
+		{
+			name: "Metadata",
+			list: &List{
+				Owner: " platform-team ",
+				URL:   "https://example.com/wiki",
+				Deny: map[string]string{
+					"os":      "Use the config package",
+					"reflect": "Don't use Reflect",
+				},
+				DenyMetadata: map[string]*Metadata{
+					"reflect": {Owner: "core-team", Reason: "Reflection hides bugs"},
+				},
+			},
+			exp: &list{
+				deny:        []string{"os", "reflect"},
+				suggestions: []string{"Use the config package", "Don't use Reflect"},
+				metadata:    Metadata{Owner: "platform-team", URL: "https://example.com/wiki"},
+				denyMetadata: []Metadata{
+					{},
+					{Owner: "core-team", Reason: "Reflection hides bugs"},
+				},
+			},
+		},
+		{
+			name: "Metadata Without Deny Entry",
+			list: &List{
+				Deny: map[string]string{
+					"reflect": "Don't use Reflect",
+				},
+				DenyMetadata: map[string]*Metadata{
+					"unsafe": {Owner: "core-team"},
+				},
+			},
+			expErr: errors.New("metadata for unsafe has no matching deny entry"),
+		},
+		{
+			name: "Deny Symbols",
+			list: &List{
+				DenySymbols: map[string]string{
+					"os.Exit":                 "Return an error instead",
+					"net/http.DefaultClient$": "Build a client with timeouts",
+				},
+			},
+			exp: &list{
+				denySymbols:       []string{"net/http.DefaultClient$", "os.Exit"},
+				symbolSuggestions: []string{"Build a client with timeouts", "Return an error instead"},
+			},
+		},
+		{
+			name: "Deny Symbol Without Name",
+			list: &List{
+				DenySymbols: map[string]string{
+					"net/http": "Not a symbol",
+				},
+			},
+			expErr: errors.New("net/http is not a symbol"),
+		},
+		{
+			name: "Import Style",
+			list: &List{
+				DenyDotImports:    true,
+				AllowBlankImports: []string{"github.com/lib/pq", "embed"},
+				Aliases: map[string][]string{
+					"k8s.io/apimachinery/pkg/apis/meta/v1": {"metav1"},
+					"github.com/pkg/errors":                {"!errors", "pkgerrors"},
+				},
+			},
+			exp: &list{
+				denyDotImports: true,
+				restrictBlank:  true,
+				allowBlank:     []string{"embed", "github.com/lib/pq"},
+				aliases: map[string]*aliasRule{
+					"k8s.io/apimachinery/pkg/apis/meta/v1": {allowed: []string{"metav1"}},
+					"github.com/pkg/errors":                {allowed: []string{"pkgerrors"}, denied: []string{"errors"}},
+				},
+			},
+		},
+		{
+			name: "Deny Blank Imports",
+			list: &List{
+				DenyBlankImports: true,
+			},
+			exp: &list{
+				restrictBlank: true,
+			},
+		},
+		{
+			name: "Deny Dirs",
+			list: &List{
+				DenyDirs: []string{"cmd/**", "/tools/**/"},
+			},
+			exp: &list{
+				denyDirs: []*dirRule{
+					{pattern: "cmd/**", glob: glob.MustCompile("cmd/**", '/')},
+					{pattern: "tools/**", glob: glob.MustCompile("tools/**", '/')},
+				},
+			},
+		},
+		{
+			name: "Version Constraints",
+			list: &List{
+				Allow: []string{"github.com/golang-jwt/jwt/v4@>=v4.5.0", "os"},
+				Deny:  map[string]string{"github.com/gobwas/glob@<v0.2.3": "Upgrade"},
+			},
+			exp: &list{
+				allow: []string{"github.com/golang-jwt/jwt/v4", "os"},
+				allowVersions: map[string]*versionConstraint{
+					"github.com/golang-jwt/jwt/v4": {raw: ">=v4.5.0", bounds: []versionBound{{op: ">=", version: "v4.5.0"}}},
+				},
+				deny:        []string{"github.com/gobwas/glob"},
+				suggestions: []string{"Upgrade"},
+				denyVersions: map[string]*versionConstraint{
+					"github.com/gobwas/glob": {raw: "<v0.2.3", bounds: []versionBound{{op: "<", version: "v0.2.3"}}},
+				},
+			},
+		},
+		{
+			name: "Invalid Version Constraint",
+			list: &List{
+				Allow: []string{"github.com/golang-jwt/jwt/v4@>=4.5"},
+			},
+			expErr: errors.New("github.com/golang-jwt/jwt/v4@>=4.5 has an invalid version constraint \">=4.5\""),
+		},
+		{
+			name: "Licenses",
+			list: &List{
+				AllowLicenses: []string{"MIT", "BSD-*"},
+				DenyLicenses:  map[string]string{"GPL-*": " Legal says no ", "AGPL-*": ""},
+			},
+			exp: &list{
+				allowLicenses: []*licenseRule{
+					{pattern: "MIT", glob: glob.MustCompile("MIT")},
+					{pattern: "BSD-*", glob: glob.MustCompile("BSD-*")},
+				},
+				denyLicenses: []*licenseRule{
+					{pattern: "AGPL-*", glob: glob.MustCompile("AGPL-*")},
+					{pattern: "GPL-*", glob: glob.MustCompile("GPL-*"), suggestion: "Legal says no"},
+				},
+			},
+		},
+		{
+			name: "Deny Deprecated",
+			list: &List{
+				DenyDeprecated: true,
+			},
+			exp: &list{
+				denyDeprecated: true,
+			},
+		},
+		{
+			name: "Deny Retracted",
+			list: &List{
+				DenyRetracted: true,
+			},
+			exp: &list{
+				denyRetracted: true,
+			},
+		},
+		{
+			name: "Replacements",
+			list: &List{
+				DenyLocalReplace: true,
+				RequireReplace:   map[string]string{"example.com/lib": " example.com/fork "},
+				VendoredOnly:     []string{"golang.org/x/", "example.com/lib$"},
+			},
+			exp: &list{
+				denyLocalReplace: true,
+				requireReplace:   map[string]string{"example.com/lib": "example.com/fork"},
+				vendoredOnly:     []string{"example.com/lib$", "golang.org/x/"},
+			},
+		},
+		{
+			name: "Module Order",
+			list: &List{
+				ModuleOrder: []string{"example.com/core", "example.com/*"},
+			},
+			exp: &list{
+				moduleOrder: []*dirRule{
+					{pattern: "example.com/core", glob: glob.MustCompile("example.com/core", '/')},
+					{pattern: "example.com/*", glob: glob.MustCompile("example.com/*", '/')},
+				},
+			},
+		},
+		{
+			name: "Deny Module Cycles",
+			list: &List{
+				DenyModuleCycles: true,
+			},
+			exp: &list{
+				denyModuleCycles: true,
+			},
+		},
+		{
+			name: "Import Budgets",
+			list: &List{
+				MaxImports:         10,
+				MaxExternalModules: 5,
+			},
+			exp: &list{
+				maxImports: 10,
+				maxModules: 5,
+			},
+		},
+		{
+			name: "Negative Import Budget",
+			list: &List{
+				Deny:       map[string]string{"reflect": ""},
+				MaxImports: -1,
+			},
+			expErr: errors.New("maxImports and maxExternalModules can not be negative"),
+		},
+		{
+			name: "Visibility",
+			list: &List{
+				Visibility: map[string][]string{
+					"pkg/billing/**": {"services/billing/**", "services/invoices/**"},
+					"pkg/auth":       {"services/**"},
+				},
+			},
+			exp: &list{
+				visibility: []*visibilityRule{
+					{
+						pattern:   "pkg/auth",
+						glob:      glob.MustCompile("pkg/auth", '/'),
+						importers: []string{"services/**"},
+						globs:     []glob.Glob{glob.MustCompile("services{,/**}", '/')},
+					},
+					{
+						pattern:   "pkg/billing/**",
+						glob:      glob.MustCompile("pkg/billing{,/**}", '/'),
+						importers: []string{"services/billing/**", "services/invoices/**"},
+						globs: []glob.Glob{
+							glob.MustCompile("services/billing{,/**}", '/'),
+							glob.MustCompile("services/invoices{,/**}", '/'),
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Failure to Compile Visibility Importer",
+			list: &List{
+				Visibility: map[string][]string{
+					"pkg/billing/**": {"[a-]/foo"},
+				},
+			},
+			expErr: errors.New("[a-]/foo could not be compiled"),
+		},
+		{
+			name: "Deny Test Only",
+			list: &List{
+				DenyTestOnly: true,
+			},
+			exp: &list{
+				testFiles: []glob.Glob{
+					glob.MustCompile("**/*_test.go", '/'),
+				},
+				testOnly: []string{"github.com/stretchr/testify", "net/http/httptest", "testing"},
+			},
+		},
+		{
+			name: "Empty Aliases",
+			list: &List{
+				Aliases: map[string][]string{
+					"github.com/pkg/errors": {},
+				},
+			},
+			expErr: errors.New("aliases for github.com/pkg/errors must not be empty"),
+		},
 		{
 			name: "Unknown List Mode",
 			list: &List{
@@ -233,6 +619,25 @@ var (
 			},
 			expErr: errors.New("MiddleOut is not a known list mode"),
 		},
+		{
+			name: "Severity",
+			list: &List{
+				Severity: " Warning ",
+				Allow:    []string{"os"},
+			},
+			exp: &list{
+				severity: "warning",
+				allow:    []string{"os"},
+			},
+		},
+		{
+			name: "Unknown Severity",
+			list: &List{
+				Severity: "fatal",
+				Allow:    []string{"os"},
+			},
+			expErr: errors.New("fatal is not a known severity"),
+		},
 	}
 	settingsCompileScenarios = []*settingsCompileScenario{
 		{
@@ -243,7 +648,8 @@ var (
 					files: []glob.Glob{
 						glob.MustCompile("**/*.go", '/'),
 					},
-					allow: []string{"FIND ME", "FIND ME TOO"},
+					allow:          []string{"FIND ME", "FIND ME TOO"},
+					allowVariables: map[string]string{"FIND ME": "$gostd", "FIND ME TOO": "$gostd"},
 				},
 			},
 		},
@@ -279,6 +685,14 @@ var (
 	}
 )
 
+// templateComparer compares message templates by their parsed source.
+var templateComparer = cmp.Comparer(func(a, b *template.Template) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Root.String() == b.Root.String()
+})
+
 func testListCompile(s *listCompileScenario) func(*testing.T) {
 	return func(t *testing.T) {
 		act, err := s.list.compile()
@@ -294,7 +708,7 @@ func testListCompile(s *listCompileScenario) func(*testing.T) {
 		if err != nil {
 			t.Fatal("not expecting an error")
 		}
-		diff := cmp.Diff(s.exp, act, cmp.AllowUnexported(list{}))
+		diff := cmp.Diff(s.exp, act, cmp.AllowUnexported(list{}, aliasRule{}, dirRule{}, visibilityRule{}, versionConstraint{}, versionBound{}, licenseRule{}), templateComparer)
 		if diff != "" {
 			t.Errorf("compiled list is not what was expected\n%s", diff)
 		}
@@ -316,7 +730,7 @@ func testSettingsCompile(s *settingsCompileScenario) func(*testing.T) {
 		if err != nil {
 			t.Fatal("not expecting an error")
 		}
-		diff := cmp.Diff(s.exp, act, cmp.AllowUnexported(list{}))
+		diff := cmp.Diff(s.exp, act, cmp.AllowUnexported(list{}, aliasRule{}, dirRule{}, visibilityRule{}, versionConstraint{}, versionBound{}, licenseRule{}), templateComparer)
 		if diff != "" {
 			t.Errorf("compiled settings is not what was expected\n%s", diff)
 		}
@@ -867,12 +1281,12 @@ func TestListImportAllowed(t *testing.T) {
 		t.Run(s.name, func(ts *testing.T) {
 			for _, sc := range s.tests {
 				ts.Run(sc.name, func(tst *testing.T) {
-					act, sugg := s.setup.importAllowed(sc.input)
-					if act != sc.allowed {
+					act := s.setup.importAllowed(sc.input, "")
+					if act.allowed != sc.allowed {
 						tst.Error("Did not return expected result")
 					}
-					if sugg != sc.suggestion {
-						tst.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", sc.suggestion, sugg)
+					if act.suggestion != sc.suggestion {
+						tst.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", sc.suggestion, act.suggestion)
 					}
 				})
 			}
@@ -880,10 +1294,688 @@ func TestListImportAllowed(t *testing.T) {
 	}
 }
 
+func TestListImportAllowedRule(t *testing.T) {
+	l := &list{
+		listMode:    listModeStrict,
+		allow:       []string{"some/pkg/a/foo", "some/pkg/b"},
+		deny:        []string{"some/pkg/a", "some/pkg/b/foo$"},
+		suggestions: []string{"because I said so", "really don't use"},
+	}
+	tests := []struct {
+		input string
+		rule  string
+	}{
+		{input: "some/pkg/a/foo/bar", rule: "some/pkg/a/foo"},
+		{input: "some/pkg/a/baz", rule: "some/pkg/a"},
+		{input: "some/pkg/b/foo", rule: "some/pkg/b/foo$"},
+		{input: "some/pkg/b/baz", rule: "some/pkg/b"},
+		{input: "some/pkg/c", rule: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			if act := l.importAllowed(tc.input, ""); act.rule != tc.rule {
+				t.Errorf("Rule didn't match expected: Exp %s: Act: %s", tc.rule, act.rule)
+			}
+		})
+	}
+}
+
+func TestListImportAllowedVersion(t *testing.T) {
+	l, err := (&List{
+		ListMode: "Strict",
+		Allow:    []string{"$gostd", "github.com/golang-jwt/jwt/v4@>=v4.5.0"},
+		Deny:     map[string]string{"github.com/golang-jwt/jwt/v4/request@<v4.5.1": "Upgrade to v4.5.1"},
+	}).compile()
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	tests := []struct {
+		name       string
+		input      string
+		version    string
+		allowed    bool
+		rule       string
+		suggestion string
+	}{
+		{name: "Within Constraint", input: "github.com/golang-jwt/jwt/v4", version: "v4.5.0", allowed: true, rule: "github.com/golang-jwt/jwt/v4"},
+		{name: "Outside Of Constraint", input: "github.com/golang-jwt/jwt/v4", version: "v4.4.3", rule: "github.com/golang-jwt/jwt/v4@>=v4.5.0", suggestion: "version v4.4.3 is not >=v4.5.0"},
+		{name: "Unknown Version", input: "github.com/golang-jwt/jwt/v4", allowed: true, rule: "github.com/golang-jwt/jwt/v4"},
+		{name: "Denied Version", input: "github.com/golang-jwt/jwt/v4/request", version: "v4.5.0", rule: "github.com/golang-jwt/jwt/v4/request@<v4.5.1", suggestion: "Upgrade to v4.5.1"},
+		{name: "Not Denied Version", input: "github.com/golang-jwt/jwt/v4/request", version: "v4.5.1", allowed: true, rule: "github.com/golang-jwt/jwt/v4"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := l.importAllowed(tc.input, tc.version)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.rule != tc.rule {
+				t.Errorf("Rule didn't match expected: Exp %s: Act: %s", tc.rule, act.rule)
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListImportAllowedEntries(t *testing.T) {
+	l, err := (&List{
+		ListMode: "Strict",
+		Allow:    []string{"$gostd", "FIND ME TOO", "github.com/a@>=v1.2.0"},
+		Deny: map[string]string{
+			"github.com/a/b":       "Don't use b",
+			"github.com/c@<v2.0.0": "Upgrade to v2",
+		},
+	}).compile()
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	tests := []struct {
+		input   string
+		version string
+		allow   string
+		deny    string
+	}{
+		{input: "FIND ME", allow: "$gostd"},
+		{input: "FIND ME TOO", allow: "FIND ME TOO"},
+		{input: "github.com/a/b", version: "v1.3.0", allow: "github.com/a@>=v1.2.0", deny: "github.com/a/b"},
+		{input: "github.com/a", version: "v1.0.0"},
+		{input: "github.com/c", version: "v1.0.0", deny: "github.com/c@<v2.0.0"},
+		{input: "github.com/c", version: "v2.0.0"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input+"@"+tc.version, func(t *testing.T) {
+			act := l.importAllowed(tc.input, tc.version)
+			if act.allowEntry != tc.allow {
+				t.Errorf("Allow entry didn't match expected: Exp %s: Act: %s", tc.allow, act.allowEntry)
+			}
+			if act.denyEntry != tc.deny {
+				t.Errorf("Deny entry didn't match expected: Exp %s: Act: %s", tc.deny, act.denyEntry)
+			}
+		})
+	}
+}
+
+func TestListImportAllowedMetadata(t *testing.T) {
+	l := &list{
+		listMode:     listModeLax,
+		deny:         []string{"os", "reflect"},
+		suggestions:  []string{"", ""},
+		metadata:     Metadata{Owner: "platform-team", URL: "https://example.com/wiki"},
+		denyMetadata: []Metadata{{}, {Owner: "core-team"}},
+	}
+	exp := Metadata{Owner: "platform-team", URL: "https://example.com/wiki"}
+	if act := l.importAllowed("os", ""); act.metadata != exp {
+		t.Errorf("Metadata didn't match expected: Exp %v: Act: %v", exp, act.metadata)
+	}
+	exp = Metadata{Owner: "core-team", URL: "https://example.com/wiki"}
+	if act := l.importAllowed("reflect", ""); act.metadata != exp {
+		t.Errorf("Metadata didn't match expected: Exp %v: Act: %v", exp, act.metadata)
+	}
+}
+
+func TestListSymbolAllowed(t *testing.T) {
+	tests := []struct {
+		name       string
+		mode       listMode
+		input      string
+		allowed    bool
+		suggestion string
+	}{
+		{name: "not denied", mode: listModeStrict, input: "os.Getenv", allowed: true},
+		{name: "denied", mode: listModeStrict, input: "os.Exit", suggestion: "Return an error instead"},
+		{name: "denied by prefix", mode: listModeLax, input: "net/http.DefaultServeMux", suggestion: "Don't use globals"},
+		{name: "longer allow in lax", mode: listModeLax, input: "net/http.DefaultTransport", allowed: true},
+		{name: "longer allow in strict", mode: listModeStrict, input: "net/http.DefaultTransport", allowed: true},
+		{name: "longer allow in original", mode: listModeOriginal, input: "net/http.DefaultTransport", suggestion: "Don't use globals"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &list{
+				listMode:          tc.mode,
+				allow:             []string{"net/http", "net/http.DefaultTransport", "os"},
+				denySymbols:       []string{"net/http.Default", "os.Exit$"},
+				symbolSuggestions: []string{"Don't use globals", "Return an error instead"},
+			}
+			act := l.symbolAllowed(tc.input)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListStyleAllowed(t *testing.T) {
+	l := &list{
+		denyDotImports: true,
+		restrictBlank:  true,
+		allowBlank:     []string{"embed$", "github.com/lib/pq"},
+		aliases: map[string]*aliasRule{
+			"k8s.io/apimachinery/pkg/apis/meta/v1": {allowed: []string{"metav1"}},
+			"github.com/pkg/errors":                {denied: []string{"errors"}},
+		},
+	}
+	tests := []struct {
+		name       string
+		imp        string
+		alias      string
+		pkgName    string
+		allowed    bool
+		kind       string
+		suggestion string
+	}{
+		{name: "plain import", imp: "os", allowed: true},
+		{name: "dot import", imp: "os", alias: ".", kind: "dot"},
+		{name: "allowed blank import", imp: "github.com/lib/pq", alias: "_", allowed: true},
+		{name: "allowed exact blank import", imp: "embed", alias: "_", allowed: true},
+		{name: "denied blank import", imp: "net/http/pprof", alias: "_", kind: "blank"},
+		{name: "required alias", imp: "k8s.io/apimachinery/pkg/apis/meta/v1", alias: "metav1", allowed: true},
+		{name: "wrong alias", imp: "k8s.io/apimachinery/pkg/apis/meta/v1", alias: "v1", kind: "aliased", suggestion: "alias it as metav1"},
+		{name: "missing alias", imp: "k8s.io/apimachinery/pkg/apis/meta/v1", kind: "unaliased", suggestion: "alias it as metav1"},
+		{name: "denied alias", imp: "github.com/pkg/errors", alias: "errors", kind: "aliased"},
+		{name: "other alias", imp: "github.com/pkg/errors", alias: "pkgerrors", pkgName: "errors", allowed: true},
+		{name: "no alias", imp: "github.com/pkg/errors", pkgName: "errors", kind: "unaliased", suggestion: "alias it as something other than errors"},
+		{name: "no alias of unknown package", imp: "github.com/pkg/errors", allowed: true},
+		{name: "no alias used as required alias", imp: "k8s.io/apimachinery/pkg/apis/meta/v1", pkgName: "metav1", allowed: true},
+		{name: "no alias used as other name", imp: "k8s.io/apimachinery/pkg/apis/meta/v1", pkgName: "v1", kind: "unaliased", suggestion: "alias it as metav1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := l.styleAllowed(tc.imp, tc.alias, tc.pkgName)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.kind != tc.kind {
+				t.Errorf("Kind didn't match expected: Exp %s: Act: %s", tc.kind, act.kind)
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListDirAllowed(t *testing.T) {
+	l := &list{
+		denyDirs: []*dirRule{
+			{pattern: "cmd/**", glob: glob.MustCompile("cmd/**", '/')},
+			{pattern: "**/tools", glob: glob.MustCompile("**/tools", '/')},
+		},
+	}
+	tests := []struct {
+		dir     string
+		allowed bool
+		rule    string
+	}{
+		{dir: "cmd/tool/helpers", rule: "cmd/**"},
+		{dir: "cmd/tool", rule: "cmd/**"},
+		{dir: "internal/cmd/tool", allowed: true},
+		{dir: "internal/tools", rule: "**/tools"},
+		{dir: "pkg/foo", allowed: true},
+		{dir: ".", allowed: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.dir, func(t *testing.T) {
+			act := l.dirAllowed(tc.dir)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.rule != tc.rule {
+				t.Errorf("Rule didn't match expected: Exp %s: Act: %s", tc.rule, act.rule)
+			}
+		})
+	}
+}
+
+func TestListModuleAllowed(t *testing.T) {
+	order := &list{
+		moduleOrder: []*dirRule{
+			{pattern: "example.com/core", glob: glob.MustCompile("example.com/core", '/')},
+			{pattern: "example.com/*", glob: glob.MustCompile("example.com/*", '/')},
+		},
+	}
+	cycles := &list{
+		denyModuleCycles: true,
+	}
+	graph := &moduleGraphFact{Edges: map[string][]string{}}
+	graph.addEdge("example.com/api", "example.com/core")
+	graph.addEdge("example.com/core", "golang.org/x/mod")
+	tests := []struct {
+		name       string
+		list       *list
+		from       string
+		to         string
+		allowed    bool
+		rule       string
+		suggestion string
+	}{
+		{name: "Lower Layer", list: order, from: "example.com/api", to: "example.com/core", allowed: true},
+		{name: "Higher Layer", list: order, from: "example.com/core", to: "example.com/api", rule: "example.com/*", suggestion: "module example.com/core comes before example.com/api in the module order"},
+		{name: "Same Layer", list: order, from: "example.com/api", to: "example.com/db", allowed: true},
+		{name: "Outside Of Order", list: order, from: "golang.org/x/mod", to: "example.com/api", allowed: true},
+		{name: "Same Module", list: order, from: "example.com/core", to: "example.com/core", allowed: true},
+		{name: "Unknown Module", list: order, from: "example.com/core", to: "", allowed: true},
+		{name: "No Cycle", list: cycles, from: "example.com/web", to: "example.com/core", allowed: true},
+		{name: "Cycle", list: cycles, from: "golang.org/x/mod", to: "example.com/api", suggestion: "it creates the module cycle golang.org/x/mod -> example.com/api -> example.com/core -> golang.org/x/mod"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := tc.list.moduleAllowed(graph, tc.from, tc.to)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.rule != tc.rule {
+				t.Errorf("Rule didn't match expected: Exp %s: Act: %s", tc.rule, act.rule)
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListBudgetAllowed(t *testing.T) {
+	l := &list{}
+	tests := []struct {
+		name       string
+		items      []string
+		limit      int
+		allowed    bool
+		suggestion string
+	}{
+		{name: "No Limit", items: []string{"a.com/a", "b.com/b"}, allowed: true},
+		{name: "Under Limit", items: []string{"a.com/a"}, limit: 2, allowed: true},
+		{name: "At Limit", items: []string{"a.com/a", "b.com/b"}, limit: 2, allowed: true},
+		{name: "Over Limit", items: []string{"a.com/a", "b.com/b", "c.com/c"}, limit: 2, suggestion: "a.com/a, b.com/b, c.com/c"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := l.budgetAllowed(tc.items, tc.limit)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListLicenseAllowed(t *testing.T) {
+	deny := &list{
+		denyLicenses: []*licenseRule{
+			{pattern: "*GPL-*", glob: glob.MustCompile("*GPL-*"), suggestion: "Legal says no"},
+		},
+	}
+	allow := &list{
+		allowLicenses: []*licenseRule{
+			{pattern: "MIT", glob: glob.MustCompile("MIT")},
+			{pattern: "BSD-*", glob: glob.MustCompile("BSD-*")},
+		},
+	}
+	tests := []struct {
+		name       string
+		list       *list
+		license    string
+		allowed    bool
+		rule       string
+		suggestion string
+	}{
+		{name: "Denied", list: deny, license: "AGPL-3.0", rule: "*GPL-*", suggestion: "Legal says no"},
+		{name: "Not Denied", list: deny, license: "MIT", allowed: true},
+		{name: "Unknown Not Denied", list: deny, license: "", allowed: true},
+		{name: "Allowed", list: allow, license: "BSD-3-Clause", allowed: true, rule: "BSD-*"},
+		{name: "Not Allowed", list: allow, license: "Apache-2.0", suggestion: "it is licensed under Apache-2.0"},
+		{name: "Unknown Not Allowed", list: allow, license: "", suggestion: "no known license was found"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := tc.list.licenseAllowed(tc.license)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.rule != tc.rule {
+				t.Errorf("Rule didn't match expected: Exp %s: Act: %s", tc.rule, act.rule)
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListDeprecatedAllowed(t *testing.T) {
+	tests := []struct {
+		name       string
+		list       *list
+		pkgMsg     string
+		modMsg     string
+		allowed    bool
+		suggestion string
+	}{
+		{name: "Not Denied", list: &list{}, pkgMsg: "use bar", allowed: true},
+		{name: "Not Deprecated", list: &list{denyDeprecated: true}, allowed: true},
+		{name: "Package", list: &list{denyDeprecated: true}, pkgMsg: "use bar", modMsg: "use v2", suggestion: "use bar"},
+		{name: "Module", list: &list{denyDeprecated: true}, modMsg: "use v2", suggestion: "the module is deprecated: use v2"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := tc.list.deprecatedAllowed(tc.pkgMsg, tc.modMsg)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if !act.allowed && act.kind != "deprecated" {
+				t.Errorf("Kind didn't match expected: Exp deprecated: Act: %s", act.kind)
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListReplaceAllowed(t *testing.T) {
+	local := &modfile.Replace{New: module.Version{Path: "../lib"}}
+	fork := &modfile.Replace{New: module.Version{Path: "example.com/fork", Version: "v1.0.0"}}
+	other := &modfile.Replace{New: module.Version{Path: "example.com/other", Version: "v1.0.0"}}
+	l := &list{
+		denyLocalReplace: true,
+		requireReplace:   map[string]string{"example.com/lib": "example.com/fork"},
+	}
+	tests := []struct {
+		name       string
+		mod        string
+		rep        *modfile.Replace
+		allowed    bool
+		kind       string
+		suggestion string
+	}{
+		{name: "Not Replaced", mod: "example.com/dep", allowed: true},
+		{name: "Replaced With Module", mod: "example.com/dep", rep: other, allowed: true},
+		{name: "Replaced Locally", mod: "example.com/dep", rep: local, kind: "replaced", suggestion: "it is replaced with the local directory ../lib"},
+		{name: "Required Fork", mod: "example.com/lib", rep: fork, allowed: true},
+		{name: "Missing Fork", mod: "example.com/lib", suggestion: "replace it with example.com/fork"},
+		{name: "Wrong Fork", mod: "example.com/lib", rep: other, suggestion: "replace it with example.com/fork"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := l.replaceAllowed(tc.mod, tc.rep)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.kind != tc.kind {
+				t.Errorf("Kind didn't match expected: Exp %s: Act: %s", tc.kind, act.kind)
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListVendoredAllowed(t *testing.T) {
+	l := &list{vendoredOnly: []string{"example.com/lib$", "golang.org/x/"}}
+	tests := []struct {
+		input    string
+		vendored bool
+		allowed  bool
+		rule     string
+	}{
+		{input: "golang.org/x/mod/semver", vendored: true, allowed: true},
+		{input: "golang.org/x/mod/semver", rule: "golang.org/x/"},
+		{input: "example.com/lib", rule: "example.com/lib$"},
+		{input: "example.com/lib/sub", allowed: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			act := l.vendoredAllowed(tc.input, tc.vendored)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.rule != tc.rule {
+				t.Errorf("Rule didn't match expected: Exp %s: Act: %s", tc.rule, act.rule)
+			}
+		})
+	}
+}
+
+func TestListRetractedAllowed(t *testing.T) {
+	retracts := []*modfile.Retract{
+		{VersionInterval: modfile.VersionInterval{Low: "v1.0.1", High: "v1.0.1"}, Rationale: "Published with a data race."},
+		{VersionInterval: modfile.VersionInterval{Low: "v1.1.0", High: "v1.1.5"}},
+	}
+	tests := []struct {
+		name       string
+		list       *list
+		version    string
+		allowed    bool
+		rule       string
+		suggestion string
+	}{
+		{name: "Not Denied", list: &list{}, version: "v1.0.1", allowed: true},
+		{name: "Unknown Version", list: &list{denyRetracted: true}, allowed: true},
+		{name: "Not Retracted", list: &list{denyRetracted: true}, version: "v1.0.2", allowed: true},
+		{name: "Retracted", list: &list{denyRetracted: true}, version: "v1.0.1", rule: "v1.0.1", suggestion: "version v1.0.1 is retracted: Published with a data race."},
+		{name: "Retracted Range", list: &list{denyRetracted: true}, version: "v1.1.2", rule: "[v1.1.0, v1.1.5]", suggestion: "version v1.1.2 is retracted"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := tc.list.retractedAllowed(retracts, tc.version)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.rule != tc.rule {
+				t.Errorf("Rule didn't match expected: Exp %s: Act: %s", tc.rule, act.rule)
+			}
+			if act.suggestion != tc.suggestion {
+				t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", tc.suggestion, act.suggestion)
+			}
+		})
+	}
+}
+
+func TestListVulnAllowed(t *testing.T) {
+	vuln := &osvEntry{
+		ID:       "GO-2099-0001",
+		Summary:  "Catastrophic backtracking in glob",
+		Affected: []osvAffected{{Ranges: []osvRange{{Type: "SEMVER", Events: []osvEvent{{Introduced: "0"}, {Fixed: "1.2.0"}}}}}},
+	}
+	vuln.Affected[0].Package.Name = "example.com/mod"
+	l := &list{vulnDB: "/vulns"}
+	if act := l.vulnAllowed(nil, "example.com/mod", "v1.1.0"); !act.allowed {
+		t.Error("Did not return expected result")
+	}
+	act := l.vulnAllowed([]*osvEntry{vuln}, "example.com/mod", "v1.1.0")
+	if act.allowed {
+		t.Error("Did not return expected result")
+	}
+	if act.rule != "GO-2099-0001" {
+		t.Errorf("Rule didn't match expected: Exp GO-2099-0001: Act: %s", act.rule)
+	}
+	exp := "GO-2099-0001: Catastrophic backtracking in glob (fixed in v1.2.0)"
+	if act.suggestion != exp {
+		t.Errorf("Suggestion didn't match expected: Exp %s: Act: %s", exp, act.suggestion)
+	}
+}
+
+func TestListVisibilityAllowed(t *testing.T) {
+	l, err := (&List{
+		Visibility: map[string][]string{
+			"pkg/billing/**": {"services/billing/**", "services/invoices/**"},
+		},
+	}).compile()
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	tests := []struct {
+		name     string
+		imp      string
+		importer string
+		allowed  bool
+	}{
+		{name: "visible", imp: "example.com/mono/pkg/billing", importer: "example.com/mono/services/billing/api", allowed: true},
+		{name: "visible to tree root", imp: "example.com/mono/pkg/billing", importer: "example.com/mono/services/billing", allowed: true},
+		{name: "not visible to sibling", imp: "example.com/mono/pkg/billing", importer: "example.com/mono/services/billingv2"},
+		{name: "visible sub package", imp: "example.com/mono/pkg/billing/tax", importer: "example.com/mono/services/invoices/api", allowed: true},
+		{name: "not visible", imp: "example.com/mono/pkg/billing/tax", importer: "example.com/mono/services/users"},
+		{name: "same subtree", imp: "example.com/mono/pkg/billing/tax", importer: "example.com/mono/pkg/billing", allowed: true},
+		{name: "not governed", imp: "example.com/mono/pkg/users", importer: "example.com/mono/services/users", allowed: true},
+		{name: "other module", imp: "example.com/other/pkg/billing", importer: "example.com/mono/services/users", allowed: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := l.visibilityAllowed(packageNames(tc.imp, "example.com/mono"), packageNames(tc.importer, "example.com/mono"))
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+		})
+	}
+}
+
+func TestPackageNames(t *testing.T) {
+	if diff := cmp.Diff([]string{"example.com/mono/pkg/a", "pkg/a"}, packageNames("example.com/mono/pkg/a", "example.com/mono")); diff != "" {
+		t.Errorf("package names in module did not match\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"example.com/monorepo"}, packageNames("example.com/monorepo", "example.com/mono")); diff != "" {
+		t.Errorf("package names outside of module did not match\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"os"}, packageNames("os", "")); diff != "" {
+		t.Errorf("package names without module did not match\n%s", diff)
+	}
+}
+
+func TestListTestOnlyAllowed(t *testing.T) {
+	l := &list{
+		testFiles: []glob.Glob{glob.MustCompile("**/*_test.go", '/')},
+		testOnly:  []string{"github.com/stretchr/testify", "net/http/httptest", "testing"},
+	}
+	tests := []struct {
+		name     string
+		imp      string
+		importer string
+		fileName string
+		allowed  bool
+		rule     string
+	}{
+		{name: "testing in test", imp: "testing", fileName: "/some/foo_test.go", allowed: true},
+		{name: "testing in testutil", imp: "testing", importer: "example.com/internal/testutil", fileName: "/some/internal/testutil/t.go", allowed: true},
+		{name: "testing in test main", imp: "testing", importer: "example.com/app.test", fileName: "/cache/go-build/ab/abcdef-d", allowed: true},
+		{name: "testify in test only package", imp: "github.com/stretchr/testify/assert", importer: "net/http/httptest", fileName: "/some/server.go", allowed: true},
+		{name: "testing in code", imp: "testing", importer: "example.com/app", fileName: "/some/foo.go", rule: "testing"},
+		{name: "sub package in code", imp: "github.com/stretchr/testify/require", importer: "example.com/app", fileName: "/some/foo.go", rule: "github.com/stretchr/testify"},
+		{name: "testutil in code", imp: "example.com/internal/testutil", importer: "example.com/app", fileName: "/some/foo.go"},
+		{name: "testutil in test", imp: "example.com/internal/testutil", fileName: "/some/foo_test.go", allowed: true},
+		{name: "other in code", imp: "os", fileName: "/some/foo.go", allowed: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := l.testOnlyAllowed(tc.imp, tc.importer, tc.fileName)
+			if act.allowed != tc.allowed {
+				t.Error("Did not return expected result")
+			}
+			if act.rule != tc.rule {
+				t.Errorf("Rule didn't match expected: Exp %s: Act: %s", tc.rule, act.rule)
+			}
+		})
+	}
+	if act := (&list{}).testOnlyAllowed("testing", "example.com/app", "/some/foo.go"); !act.allowed {
+		t.Error("lists not denying test only packages should allow them")
+	}
+}
+
+func TestListFormatMessage(t *testing.T) {
+	data := &messageData{
+		Import:      "reflect",
+		List:        "Main",
+		File:        "/some/file.go",
+		Suggestion:  "Who needs reflection",
+		MatchedRule: "reflect",
+		Mode:        "Strict",
+	}
+	t.Run("default", func(t *testing.T) {
+		act, err := (&list{name: "Main"}).formatMessage(data)
+		if err != nil {
+			t.Fatal("not expecting an error")
+		}
+		exp := "import 'reflect' is not allowed from list 'Main': Who needs reflection"
+		if act != exp {
+			t.Errorf("Message didn't match expected: Exp %s: Act: %s", exp, act)
+		}
+	})
+	t.Run("default with metadata", func(t *testing.T) {
+		data := *data
+		data.Owner = "core-team"
+		data.URL = "https://example.com/wiki"
+		act, err := (&list{name: "Main"}).formatMessage(&data)
+		if err != nil {
+			t.Fatal("not expecting an error")
+		}
+		exp := "import 'reflect' is not allowed from list 'Main': Who needs reflection [owner: core-team] [url: https://example.com/wiki]"
+		if act != exp {
+			t.Errorf("Message didn't match expected: Exp %s: Act: %s", exp, act)
+		}
+	})
+	t.Run("default for symbols", func(t *testing.T) {
+		data := &messageData{Import: "os", Symbol: "os.Exit", List: "Main"}
+		act, err := (&list{name: "Main"}).formatMessage(data)
+		if err != nil {
+			t.Fatal("not expecting an error")
+		}
+		exp := "use of 'os.Exit' is not allowed from list 'Main'"
+		if act != exp {
+			t.Errorf("Message didn't match expected: Exp %s: Act: %s", exp, act)
+		}
+	})
+	t.Run("default for import budgets", func(t *testing.T) {
+		data := &messageData{Package: "example.com/pkg", Kind: "imports", Count: 3, Limit: 2, List: "Main", Suggestion: "a.com/a, b.com/b, c.com/c"}
+		act, err := (&list{name: "Main"}).formatMessage(data)
+		if err != nil {
+			t.Fatal("not expecting an error")
+		}
+		exp := "package 'example.com/pkg' has 3 imports, more than the 2 allowed from list 'Main': a.com/a, b.com/b, c.com/c"
+		if act != exp {
+			t.Errorf("Message didn't match expected: Exp %s: Act: %s", exp, act)
+		}
+	})
+	t.Run("default for import style", func(t *testing.T) {
+		data := &messageData{Import: "os", Kind: "dot", List: "Main"}
+		act, err := (&list{name: "Main"}).formatMessage(data)
+		if err != nil {
+			t.Fatal("not expecting an error")
+		}
+		exp := "dot import 'os' is not allowed from list 'Main'"
+		if act != exp {
+			t.Errorf("Message didn't match expected: Exp %s: Act: %s", exp, act)
+		}
+	})
+	t.Run("custom", func(t *testing.T) {
+		l := &list{
+			name:    "Main",
+			message: template.Must(template.New("message").Parse("{{.Mode}} list {{.List}} matched {{.MatchedRule}} in {{.File}}")),
+		}
+		act, err := l.formatMessage(data)
+		if err != nil {
+			t.Fatal("not expecting an error")
+		}
+		exp := "Strict list Main matched reflect in /some/file.go"
+		if act != exp {
+			t.Errorf("Message didn't match expected: Exp %s: Act: %s", exp, act)
+		}
+	})
+}
+
 type linterSettingsWhichListsScenario struct {
-	name     string
-	input    string
-	expected []string
+	name       string
+	input      string
+	pkgPath    string
+	moduleRoot string
+	constraint string
+	generated  bool
+	expected   []string
 }
 
 var linterSettingsWhichListsSetup = linterSettings{
@@ -899,6 +1991,42 @@ var linterSettingsWhichListsSetup = linterSettings{
 			glob.MustCompile("**/*_test.go", '/'),
 		},
 	},
+	{
+		name: "Internal",
+		packages: []glob.Glob{
+			glob.MustCompile("example.com/**/internal/**", '/'),
+		},
+		negPackages: []glob.Glob{
+			glob.MustCompile("example.com/**/internal/legacy", '/'),
+		},
+	},
+	{
+		name: "Relative",
+		files: []glob.Glob{
+			glob.MustCompile("pkg/**/*.go", '/'),
+		},
+	},
+	{
+		name: "Rooted",
+		files: []glob.Glob{
+			glob.MustCompile("tools/*.go", '/'),
+		},
+		root: "/other/root",
+	},
+	{
+		name:      "Windows",
+		files:     []glob.Glob{glob.MustCompile("**/*.go", '/')},
+		buildTags: [][]string{{"windows"}},
+	},
+	{
+		name:      "Generated",
+		generated: true,
+	},
+	{
+		name:         "Handwritten",
+		files:        []glob.Glob{glob.MustCompile("**/*.pb.go", '/')},
+		negGenerated: true,
+	},
 }
 
 var linterSettingsWhichListsScenarios = []*linterSettingsWhichListsScenario{
@@ -917,12 +2045,90 @@ var linterSettingsWhichListsScenarios = []*linterSettingsWhichListsScenario{
 		input:    "some/random_test.go",
 		expected: []string{"Main", "Test"},
 	},
+	{
+		name:     "return by package",
+		input:    "some/random.go",
+		pkgPath:  "example.com/mod/internal/foo",
+		expected: []string{"Main", "Internal"},
+	},
+	{
+		name:     "return by package only",
+		input:    "some/randome.file",
+		pkgPath:  "example.com/mod/internal/foo",
+		expected: []string{"Internal"},
+	},
+	{
+		name:       "return relative to module root",
+		input:      "/home/dev/mod/pkg/foo/bar.go",
+		moduleRoot: "/home/dev/mod",
+		expected:   []string{"Main", "Relative"},
+	},
+	{
+		name:       "return relative to module root outside of it",
+		input:      "/home/dev/other/pkg/foo/bar.go",
+		moduleRoot: "/home/dev/mod",
+		expected:   []string{"Main"},
+	},
+	{
+		name:       "return relative to list root",
+		input:      "/other/root/tools/gen.go",
+		moduleRoot: "/other",
+		expected:   []string{"Main", "Rooted"},
+	},
+	{
+		name:       "return by build constraint",
+		input:      "some/random.go",
+		constraint: "windows || plan9",
+		expected:   []string{"Main", "Windows"},
+	},
+	{
+		name:       "return by other build constraint",
+		input:      "some/random.go",
+		constraint: "linux",
+		expected:   []string{"Main"},
+	},
+	{
+		name:      "return generated",
+		input:     "some/random.go",
+		generated: true,
+		expected:  []string{"Main", "Generated"},
+	},
+	{
+		name:      "return negated generated",
+		input:     "some/random.pb.go",
+		generated: true,
+		expected:  []string{"Main", "Generated"},
+	},
+	{
+		name:     "return not generated",
+		input:    "some/random.pb.go",
+		expected: []string{"Main", "Handwritten"},
+	},
+	{
+		name:     "return by negated package",
+		input:    "some/random.go",
+		pkgPath:  "example.com/mod/internal/legacy",
+		expected: []string{"Main"},
+	},
 }
 
 func TestLinterSettingsWhichLists(t *testing.T) {
 	for _, s := range linterSettingsWhichListsScenarios {
 		t.Run(s.name, func(ts *testing.T) {
-			act := linterSettingsWhichListsSetup.whichLists(s.input)
+			src := &source{
+				fileName:   s.input,
+				pkgPath:    s.pkgPath,
+				moduleRoot: s.moduleRoot,
+				generated:  s.generated,
+			}
+			if s.constraint != "" {
+				expr, err := constraint.Parse("//go:build " + s.constraint)
+				if err != nil {
+					ts.Fatal(err)
+				}
+				src.constraint = expr
+			}
+			act := linterSettingsWhichListsSetup.whichLists(src)
 			if len(act) != len(s.expected) {
 				ts.Fatal("List is not of expected length")
 			}
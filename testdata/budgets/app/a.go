@@ -0,0 +1,12 @@
+package app // want `package 'example.com/budgets/app' has 4 imports, more than the 3 allowed from list 'Main'` `package 'example.com/budgets/app' has 3 external modules, more than the 2 allowed from list 'Main'`
+
+import (
+	"fmt"
+
+	"example.com/budgets/app/internal/local"
+	"example.com/ext1/x"
+	"example.com/ext1/y"
+	"example.com/ext2"
+)
+
+var Sum = fmt.Sprint(x.X + y.Y + ext2.Z + local.L)
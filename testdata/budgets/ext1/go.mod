@@ -0,0 +1,3 @@
+module example.com/ext1
+
+go 1.21
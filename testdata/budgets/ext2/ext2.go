@@ -0,0 +1,3 @@
+package ext2
+
+const Z = 1
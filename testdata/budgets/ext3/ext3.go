@@ -0,0 +1,3 @@
+package ext3
+
+const W = 1
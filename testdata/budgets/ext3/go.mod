@@ -0,0 +1,3 @@
+module example.com/ext3
+
+go 1.21
@@ -0,0 +1,12 @@
+package generated
+
+import (
+	"fmt"
+
+	"example.com/budgets/generated/internal/local"
+	"example.com/ext1/x"
+	"example.com/ext1/y"
+	"example.com/ext2"
+)
+
+var Sum = fmt.Sprint(x.X + y.Y + ext2.Z + local.L)
@@ -0,0 +1,3 @@
+package local
+
+const L = 1
@@ -0,0 +1,15 @@
+module example.com/budgets
+
+go 1.21
+
+require (
+	example.com/ext1 v0.0.0
+	example.com/ext2 v0.0.0
+	example.com/ext3 v0.0.0
+)
+
+replace (
+	example.com/ext1 => ./ext1
+	example.com/ext2 => ./ext2
+	example.com/ext3 => ./ext3
+)
@@ -0,0 +1,10 @@
+package app
+
+// int two() { return 2; }
+import "C" // want `cgo import 'C' is not allowed from list 'NoCgo': keep builds static`
+
+import "unsafe" // want `import 'unsafe' is not allowed from list 'NoCgo'`
+
+func Two() int {
+	return int(C.two()) + int(unsafe.Sizeof(0))
+}
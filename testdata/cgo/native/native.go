@@ -0,0 +1,8 @@
+package native
+
+// int one() { return 1; }
+import "C"
+
+func One() int {
+	return int(C.one())
+}
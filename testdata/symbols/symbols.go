@@ -0,0 +1,23 @@
+package symbols
+
+import (
+	"os"
+	"strings"
+)
+
+func Clean(s string) string {
+	parts := strings.Split(s, ",") // want `use of 'strings.Split' is not allowed from list 'Main': use strings.SplitN`
+	parts = strings.SplitN(s, ",", 2)
+	s = strings.TrimSpace(parts[0])      // want `use of 'strings.TrimSpace' is not allowed from list 'Main'`
+	s = strings.TrimPrefix(s, "> ")      // want `use of 'strings.TrimPrefix' is not allowed from list 'Main'`
+	s = strings.Replace(s, "a", "b", -1) // want `use of 'strings.Replace' is not allowed from list 'Main': use a strings.Replacer`
+	s = strings.ReplaceAll(s, "c", "d")
+	// The method of the same name isn't a package level symbol
+	r := strings.NewReplacer("e", "f")
+	return r.Replace(s)
+}
+
+func Exit(code int) {
+	exit := os.Exit // want `use of 'os.Exit' is not allowed from list 'Main': return an error instead`
+	exit(code)
+}
@@ -0,0 +1,11 @@
+package app
+
+import (
+	"testing" // want `test-only import 'testing' is not allowed from list 'Main': only import it from _test.go files`
+
+	"example.com/testonly/internal/testutil" // want `test-only import 'example.com/testonly/internal/testutil' is not allowed from list 'Main'`
+)
+
+func Run(t *testing.T) {
+	testutil.Helper(t)
+}
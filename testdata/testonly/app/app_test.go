@@ -0,0 +1,11 @@
+package app
+
+import (
+	"testing"
+
+	"example.com/testonly/internal/testutil"
+)
+
+func TestRun(t *testing.T) {
+	testutil.Helper(t)
+}
@@ -0,0 +1,8 @@
+// Package testutil is a test helper, which may import test only packages itself.
+package testutil
+
+import "testing"
+
+func Helper(t *testing.T) {
+	t.Helper()
+}
@@ -0,0 +1,94 @@
+package depguard
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// versionBound is a single comparison of a version constraint, like ">=v4.5.0".
+type versionBound struct {
+	op      string
+	version string
+}
+
+// versionConstraint is the part after the '@' of an allow or deny entry. A version
+// satisfies it when it satisfies every comma separated bound.
+type versionConstraint struct {
+	raw    string
+	bounds []versionBound
+}
+
+// versionOps are the comparisons a bound can use, longest first so "<=" isn't read as "<".
+var versionOps = []string{">=", "<=", "!=", ">", "<", "="}
+
+// splitVersion splits an entry into the package and its version constraint, if it has one.
+func splitVersion(entry string) (string, *versionConstraint, error) {
+	pkg, raw, found := strings.Cut(entry, "@")
+	if !found {
+		return entry, nil, nil
+	}
+	vc := &versionConstraint{raw: raw}
+	for _, b := range strings.Split(raw, ",") {
+		b = strings.TrimSpace(b)
+		op := "="
+		for _, o := range versionOps {
+			if strings.HasPrefix(b, o) {
+				op = o
+				break
+			}
+		}
+		version := strings.TrimSpace(strings.TrimPrefix(b, op))
+		if !semver.IsValid(version) {
+			return "", nil, fmt.Errorf("%s has an invalid version constraint %q", entry, b)
+		}
+		vc.bounds = append(vc.bounds, versionBound{op: op, version: version})
+	}
+	return pkg, vc, nil
+}
+
+// satisfiedBy reports whether the version is within the constraint.
+func (vc *versionConstraint) satisfiedBy(version string) bool {
+	for _, b := range vc.bounds {
+		c := semver.Compare(version, b.version)
+		var ok bool
+		switch b.op {
+		case ">=":
+			ok = c >= 0
+		case "<=":
+			ok = c <= 0
+		case ">":
+			ok = c > 0
+		case "<":
+			ok = c < 0
+		case "!=":
+			ok = c != 0
+		default:
+			ok = c == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// importVersion returns the version of the module providing an import path
+// from the required versions, empty if it isn't required.
+func importVersion(path string, versions map[string]string) string {
+	_, version := requiredModule(path, versions)
+	return version
+}
+
+// requiredModule returns the required module providing an import path and its
+// version, both empty if it isn't required.
+func requiredModule(path string, versions map[string]string) (string, string) {
+	var mod string
+	for m := range versions {
+		if inModule(path, m) && len(m) > len(mod) {
+			mod = m
+		}
+	}
+	return mod, versions[mod]
+}
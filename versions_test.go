@@ -0,0 +1,108 @@
+package depguard
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitVersion(t *testing.T) {
+	tests := []struct {
+		entry  string
+		pkg    string
+		exp    *versionConstraint
+		hasErr bool
+	}{
+		{entry: "github.com/golang-jwt/jwt", pkg: "github.com/golang-jwt/jwt"},
+		{
+			entry: "github.com/golang-jwt/jwt@>=v4.5.0",
+			pkg:   "github.com/golang-jwt/jwt",
+			exp:   &versionConstraint{raw: ">=v4.5.0", bounds: []versionBound{{op: ">=", version: "v4.5.0"}}},
+		},
+		{
+			entry: "golang.org/x/mod@>=v0.10.0, <v1.0.0",
+			pkg:   "golang.org/x/mod",
+			exp: &versionConstraint{raw: ">=v0.10.0, <v1.0.0", bounds: []versionBound{
+				{op: ">=", version: "v0.10.0"},
+				{op: "<", version: "v1.0.0"},
+			}},
+		},
+		{
+			entry: "golang.org/x/mod@v0.16.0",
+			pkg:   "golang.org/x/mod",
+			exp:   &versionConstraint{raw: "v0.16.0", bounds: []versionBound{{op: "=", version: "v0.16.0"}}},
+		},
+		{entry: "golang.org/x/mod@>=0.16.0", hasErr: true},
+		{entry: "golang.org/x/mod@", hasErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.entry, func(t *testing.T) {
+			pkg, act, err := splitVersion(tc.entry)
+			if tc.hasErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal("not expecting an error", err)
+			}
+			if pkg != tc.pkg {
+				t.Errorf("Package didn't match expected: Exp %s: Act: %s", tc.pkg, pkg)
+			}
+			if diff := cmp.Diff(tc.exp, act, cmp.AllowUnexported(versionConstraint{}, versionBound{})); diff != "" {
+				t.Errorf("splitVersion() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestVersionConstraintSatisfiedBy(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		exp        bool
+	}{
+		{constraint: ">=v4.5.0", version: "v4.5.0", exp: true},
+		{constraint: ">=v4.5.0", version: "v4.4.3", exp: false},
+		{constraint: ">v4.5.0", version: "v4.5.0", exp: false},
+		{constraint: "<=v1.0.0", version: "v1.0.0", exp: true},
+		{constraint: "<v1.0.0", version: "v1.0.0-rc.1", exp: true},
+		{constraint: "!=v1.2.3", version: "v1.2.3", exp: false},
+		{constraint: "=v1.2.3", version: "v1.2.3", exp: true},
+		{constraint: ">=v0.2.0,<v0.3.0", version: "v0.2.9", exp: true},
+		{constraint: ">=v0.2.0,<v0.3.0", version: "v0.3.0", exp: false},
+		{constraint: ">=v0.2.0", version: "v0.2.1-0.20240101000000-abcdefabcdef", exp: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.constraint+" "+tc.version, func(t *testing.T) {
+			_, vc, err := splitVersion("example.com/mod@" + tc.constraint)
+			if err != nil {
+				t.Fatal("not expecting an error", err)
+			}
+			if act := vc.satisfiedBy(tc.version); act != tc.exp {
+				t.Errorf("satisfiedBy() didn't match expected: Exp %t: Act: %t", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestImportVersion(t *testing.T) {
+	versions := map[string]string{
+		"golang.org/x/mod":       "v0.16.0",
+		"golang.org/x/mod/extra": "v1.0.0",
+		"github.com/gobwas/glob": "v0.2.3",
+	}
+	tests := map[string]string{
+		"golang.org/x/mod/semver":    "v0.16.0",
+		"golang.org/x/mod/extra/pkg": "v1.0.0",
+		"github.com/gobwas/glob":     "v0.2.3",
+		"golang.org/x/modules":       "",
+		"os":                         "",
+	}
+	for path, exp := range tests {
+		if act := importVersion(path, versions); act != exp {
+			t.Errorf("importVersion(%s) did not match expected: Exp %s: Act: %s", path, exp, act)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package depguard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/mod/semver"
+)
+
+// osvEntry is the part of an OSV vulnerability report that is needed to tell
+// whether an import is affected, see https://ossf.github.io/osv-schema.
+type osvEntry struct {
+	ID        string        `json:"id"`
+	Summary   string        `json:"summary"`
+	Aliases   []string      `json:"aliases"`
+	Withdrawn string        `json:"withdrawn"`
+	Affected  []osvAffected `json:"affected"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Ranges            []osvRange `json:"ranges"`
+	EcosystemSpecific struct {
+		Imports []struct {
+			Path string `json:"path"`
+		} `json:"imports"`
+	} `json:"ecosystem_specific"`
+}
+
+type osvRange struct {
+	Type   string     `json:"type"`
+	Events []osvEvent `json:"events"`
+}
+
+type osvEvent struct {
+	Introduced string `json:"introduced"`
+	Fixed      string `json:"fixed"`
+}
+
+// vulnDB holds the reports of a vulnerability database directory by the module they affect.
+type vulnDB map[string][]*osvEntry
+
+// vulnDBs caches every vulnerability database directory that has been loaded.
+var vulnDBs sync.Map
+
+// loadVulnDB reads every OSV report in a directory and its subdirectories. Other
+// JSON files, like the indexes of the Go vulnerability database, are skipped.
+func loadVulnDB(dir string) (vulnDB, error) {
+	if db, ok := vulnDBs.Load(dir); ok {
+		return db.(vulnDB), nil
+	}
+	db := make(vulnDB)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var entry osvEntry
+		if json.Unmarshal(data, &entry) != nil || entry.ID == "" || entry.Withdrawn != "" {
+			return nil
+		}
+		seen := make(map[string]bool)
+		for _, a := range entry.Affected {
+			if a.Package.Ecosystem != "Go" || seen[a.Package.Name] {
+				continue
+			}
+			seen[a.Package.Name] = true
+			db[a.Package.Name] = append(db[a.Package.Name], &entry)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not read vulnerability database %s: %w", dir, err)
+	}
+	loaded, _ := vulnDBs.LoadOrStore(dir, db)
+	return loaded.(vulnDB), nil
+}
+
+// affecting returns the reports of vulnerabilities in a package of a module at a version.
+func (db vulnDB) affecting(path, mod, version string) []*osvEntry {
+	var entries []*osvEntry
+	for _, entry := range db[mod] {
+		for _, a := range entry.Affected {
+			if a.Package.Name == mod && a.affects(path, version) {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// affects reports whether the package at the module version is affected. When the
+// report doesn't name the packages, every package of the module is.
+func (a *osvAffected) affects(path, version string) bool {
+	if imports := a.EcosystemSpecific.Imports; len(imports) > 0 {
+		var found bool
+		for _, imp := range imports {
+			if imp.Path == path {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" {
+			continue
+		}
+		var affected bool
+		for _, e := range r.Events {
+			if e.Introduced != "" && (e.Introduced == "0" || semver.Compare(version, osvVersion(e.Introduced)) >= 0) {
+				affected = true
+			}
+			if e.Fixed != "" && semver.Compare(version, osvVersion(e.Fixed)) >= 0 {
+				affected = false
+			}
+		}
+		if affected {
+			return true
+		}
+	}
+	return false
+}
+
+// fixedIn returns the lowest version after the given one that fixes the vulnerability, empty if there is none.
+func (e *osvEntry) fixedIn(mod, version string) string {
+	var fixed string
+	for _, a := range e.Affected {
+		if a.Package.Name != mod {
+			continue
+		}
+		for _, r := range a.Ranges {
+			for _, ev := range r.Events {
+				if ev.Fixed == "" {
+					continue
+				}
+				v := osvVersion(ev.Fixed)
+				if semver.Compare(v, version) > 0 && (fixed == "" || semver.Compare(v, fixed) < 0) {
+					fixed = v
+				}
+			}
+		}
+	}
+	return fixed
+}
+
+// stdlibModule is the module the Go vulnerability database reports the standard library under.
+const stdlibModule = "stdlib"
+
+// goVersionRE matches a Go release like "go1.21.3", "1.22" or "go1.23rc1".
+var goVersionRE = regexp.MustCompile(`^(?:go)?(\d+\.\d+)(?:\.(\d+))?(?:(beta|rc)(\d+))?$`)
+
+// goToolchainVersion is the version of the local go toolchain, as go env reports it
+// without switching to the toolchain a go.mod asks for, empty if it can't be found.
+var goToolchainVersion = sync.OnceValue(func() string {
+	cmd := exec.Command("go", "env", "GOVERSION")
+	cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+})
+
+// stdlibVersion returns the semver of the standard library the module in root builds
+// with: the newest of the local go toolchain, the toolchain line and the go line of
+// its go.mod, as go switches to the toolchain they ask for when the local one is older.
+// It is empty when none of them is a release.
+func stdlibVersion(root string) string {
+	version := goSemver(goToolchainVersion())
+	if mf := goModFile(root); mf != nil {
+		if mf.Toolchain != nil {
+			version = laterGoVersion(version, goSemver(mf.Toolchain.Name))
+		}
+		if mf.Go != nil {
+			version = laterGoVersion(version, goSemver(mf.Go.Version))
+		}
+	}
+	return version
+}
+
+// laterGoVersion returns the later of two versions, where empty is earlier than any.
+func laterGoVersion(a, b string) string {
+	if a == "" || (b != "" && semver.Compare(b, a) > 0) {
+		return b
+	}
+	return a
+}
+
+// goSemver turns a Go release into the semver the vulnerability database uses, so
+// "go1.21.3" is "v1.21.3", "1.22" is "v1.22.0" and "go1.23rc1" is "v1.23.0-rc.1".
+// It is empty for anything else, like a development build.
+func goSemver(version string) string {
+	m := goVersionRE.FindStringSubmatch(version)
+	if m == nil {
+		return ""
+	}
+	v := "v" + m[1] + "."
+	if m[2] != "" {
+		v += m[2]
+	} else {
+		v += "0"
+	}
+	if m[3] != "" {
+		v += "-" + m[3] + "." + m[4]
+	}
+	return v
+}
+
+// osvVersion adds the "v" prefix Go versions have and OSV reports leave out.
+func osvVersion(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
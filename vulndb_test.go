@@ -0,0 +1,161 @@
+package depguard
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testVulnReport = `{
+  "id": "GO-2099-0001",
+  "summary": "Catastrophic backtracking in glob",
+  "affected": [{
+    "package": {"name": "example.com/mod", "ecosystem": "Go"},
+    "ranges": [{"type": "SEMVER", "events": [
+      {"introduced": "0"}, {"fixed": "1.2.0"},
+      {"introduced": "1.5.0"}, {"fixed": "1.5.3"}
+    ]}],
+    "ecosystem_specific": {"imports": [{"path": "example.com/mod/glob"}]}
+  }]
+}`
+
+func TestLoadVulnDB(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		filepath.Join("ID", "GO-2099-0001.json"): testVulnReport,
+		filepath.Join("ID", "GO-2099-0002.json"): `{"id": "GO-2099-0002", "withdrawn": "2099-01-01T00:00:00Z", "affected": [{"package": {"name": "example.com/mod", "ecosystem": "Go"}}]}`,
+		filepath.Join("index", "modules.json"):   `[{"path": "example.com/mod"}]`,
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db, err := loadVulnDB(dir)
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	if len(db) != 1 || len(db["example.com/mod"]) != 1 {
+		t.Fatalf("expected only GO-2099-0001 to be loaded: %v", db)
+	}
+	tests := []struct {
+		name    string
+		path    string
+		version string
+		exp     bool
+	}{
+		{name: "Affected", path: "example.com/mod/glob", version: "v1.1.0", exp: true},
+		{name: "Fixed", path: "example.com/mod/glob", version: "v1.2.0", exp: false},
+		{name: "Reintroduced", path: "example.com/mod/glob", version: "v1.5.1", exp: true},
+		{name: "Fixed Again", path: "example.com/mod/glob", version: "v1.6.0", exp: false},
+		{name: "Other Package", path: "example.com/mod/other", version: "v1.1.0", exp: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			act := db.affecting(tc.path, "example.com/mod", tc.version)
+			if (len(act) > 0) != tc.exp {
+				t.Errorf("Affected didn't match expected: Exp %t: Act: %v", tc.exp, act)
+			}
+		})
+	}
+	if fixed := db["example.com/mod"][0].fixedIn("example.com/mod", "v1.5.1"); fixed != "v1.5.3" {
+		t.Errorf("Fixed version didn't match expected: Exp v1.5.3: Act: %s", fixed)
+	}
+	if _, err := loadVulnDB(filepath.Join(dir, "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
+
+func TestGoSemver(t *testing.T) {
+	tests := []struct {
+		version string
+		exp     string
+	}{
+		{version: "go1.21.3", exp: "v1.21.3"},
+		{version: "1.21.3", exp: "v1.21.3"},
+		{version: "1.22", exp: "v1.22.0"},
+		{version: "go1.23rc1", exp: "v1.23.0-rc.1"},
+		{version: "go1.23beta2", exp: "v1.23.0-beta.2"},
+		{version: "devel go1.24-abcdef", exp: ""},
+		{version: "default", exp: ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.version, func(t *testing.T) {
+			if act := goSemver(tc.version); act != tc.exp {
+				t.Errorf("Semver didn't match expected: Exp %q: Act: %q", tc.exp, act)
+			}
+		})
+	}
+}
+
+func TestStdlibVersion(t *testing.T) {
+	local := goToolchainVersion
+	t.Cleanup(func() { goToolchainVersion = local })
+	goToolchainVersion = func() string { return "go1.21.3" }
+	localVersion := "v1.21.3"
+	tests := []struct {
+		name  string
+		gomod string
+		exp   string
+	}{
+		{name: "Toolchain", gomod: "module example.com/app\n\ngo 1.21\n\ntoolchain go1.99.1\n", exp: "v1.99.1"},
+		{name: "Go Line", gomod: "module example.com/app\n\ngo 1.98.2\n", exp: "v1.98.2"},
+		{name: "Older Than Local", gomod: "module example.com/app\n\ngo 1.0\n", exp: localVersion},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte(tc.gomod), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if act := stdlibVersion(root); act != tc.exp {
+				t.Errorf("Version didn't match expected: Exp %q: Act: %q", tc.exp, act)
+			}
+		})
+	}
+	if act := stdlibVersion(""); act != localVersion {
+		t.Errorf("Version without a module didn't match expected: Exp %q: Act: %q", localVersion, act)
+	}
+}
+
+func TestGoToolchainVersion(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	if v := goToolchainVersion(); !strings.HasPrefix(v, "go") && !strings.HasPrefix(v, "devel") {
+		t.Errorf("expected go env to report a go version: %q", v)
+	}
+}
+
+func TestVulnDBStdlib(t *testing.T) {
+	dir := t.TempDir()
+	report := `{
+  "id": "GO-2099-0003",
+  "affected": [{
+    "package": {"name": "stdlib", "ecosystem": "Go"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "1.21.4"}]}],
+    "ecosystem_specific": {"imports": [{"path": "net/http"}]}
+  }]
+}`
+	if err := os.WriteFile(filepath.Join(dir, "GO-2099-0003.json"), []byte(report), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := loadVulnDB(dir)
+	if err != nil {
+		t.Fatal("not expecting an error", err)
+	}
+	if act := db.affecting("net/http", stdlibModule, goSemver("go1.21.3")); len(act) != 1 {
+		t.Errorf("expected net/http at go1.21.3 to be affected: %v", act)
+	}
+	if act := db.affecting("net/http", stdlibModule, goSemver("go1.21.4")); len(act) != 0 {
+		t.Errorf("expected net/http at go1.21.4 to be fixed: %v", act)
+	}
+	if fixed := db[stdlibModule][0].fixedIn(stdlibModule, goSemver("go1.21.3")); fixed != "v1.21.4" {
+		t.Errorf("Fixed version didn't match expected: Exp v1.21.4: Act: %s", fixed)
+	}
+}